@@ -0,0 +1,131 @@
+package codes
+
+// A Category groups codes by who is responsible for the failure.
+type Category int
+
+const (
+	// CategorySuccess is the category of Success.
+	CategorySuccess Category = iota
+
+	// CategoryClient groups the errors caused by the caller.
+	CategoryClient
+
+	// CategoryServer groups the errors caused by the service.
+	CategoryServer
+
+	// CategoryAuth groups the authentication and authorization errors.
+	CategoryAuth
+)
+
+// String returns a string representation of the Category.
+func (c Category) String() string {
+	switch c {
+	case CategorySuccess:
+		return "success"
+	case CategoryClient:
+		return "client"
+	case CategoryServer:
+		return "server"
+	case CategoryAuth:
+		return "auth"
+	default:
+		return "unknown"
+	}
+}
+
+// Category returns the Category of the Code. Unknown codes are
+// considered server faults.
+func (c Code) Category() Category {
+	switch c {
+	case Success:
+		return CategorySuccess
+	case InvalidToken, Unauthenticated, BadAuthenticationData, PermissionDenied:
+		return CategoryAuth
+	case BadInputData, NotFound, BadChecksum, TooBig, AlreadyExists, RateLimited, Canceled:
+		return CategoryClient
+	default:
+		return CategoryServer
+	}
+}
+
+// IsAuth reports whether the Code is an authentication or authorization failure:
+// InvalidToken, Unauthenticated, BadAuthenticationData or PermissionDenied.
+func (c Code) IsAuth() bool {
+	return c.Category() == CategoryAuth
+}
+
+// A Severity is the logging level that suits an error Code.
+type Severity int
+
+const (
+	// SeverityInfo is for outcomes that need no attention.
+	SeverityInfo Severity = iota
+
+	// SeverityWarn is for errors caused by the caller.
+	SeverityWarn
+
+	// SeverityError is for errors caused by the service, which may need paging.
+	SeverityError
+)
+
+// String returns a string representation of the Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Severity returns the Severity of the Code: Success and Canceled are info,
+// client and auth errors are warn and server errors, including unknown codes, are error.
+// Canceled is info because the client went away, so there is nothing to alert on.
+func (c Code) Severity() Severity {
+	if c == Canceled {
+		return SeverityInfo
+	}
+	switch c.Category() {
+	case CategorySuccess:
+		return SeverityInfo
+	case CategoryClient, CategoryAuth:
+		return SeverityWarn
+	default:
+		return SeverityError
+	}
+}
+
+// Symbol returns a short ASCII symbol for the Category of the Code,
+// suitable for command-line output: "OK", "AUTH", "INPUT" or "ERR".
+func (c Code) Symbol() string {
+	switch c.Category() {
+	case CategorySuccess:
+		return "OK"
+	case CategoryAuth:
+		return "AUTH"
+	case CategoryClient:
+		return "INPUT"
+	default:
+		return "ERR"
+	}
+}
+
+// TemplateName returns the name of the error page template suited for the
+// Category of the Code: "error_auth", "error_input" or "error_server".
+// Success yields "success".
+func (c Code) TemplateName() string {
+	switch c.Category() {
+	case CategorySuccess:
+		return "success"
+	case CategoryAuth:
+		return "error_auth"
+	case CategoryClient:
+		return "error_input"
+	default:
+		return "error_server"
+	}
+}
@@ -0,0 +1,123 @@
+package codes
+
+import (
+	"testing"
+)
+
+func TestCategory(t *testing.T) {
+	want := map[Code]Category{
+		Success:               CategorySuccess,
+		InvalidToken:          CategoryAuth,
+		Unauthenticated:       CategoryAuth,
+		BadAuthenticationData: CategoryAuth,
+		BadInputData:          CategoryClient,
+		Internal:              CategoryServer,
+		NotFound:              CategoryClient,
+		BadChecksum:           CategoryClient,
+		TooBig:                CategoryClient,
+		PermissionDenied:      CategoryAuth,
+		AlreadyExists:         CategoryClient,
+		RateLimited:           CategoryClient,
+		Timeout:               CategoryServer,
+		Canceled:              CategoryClient,
+		Unavailable:           CategoryServer,
+	}
+	for _, c := range AllCodes() {
+		w, ok := want[c]
+		if !ok {
+			t.Errorf("no expected category for %v", c.Name())
+			continue
+		}
+		if got := c.Category(); got != w {
+			t.Errorf("%v.Category() = %v, want %v", c.Name(), got, w)
+		}
+	}
+	if got := Code(999).Category(); got != CategoryServer {
+		t.Errorf("Code(999).Category() = %v, want server", got)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	want := map[Code]Severity{
+		Success:               SeverityInfo,
+		InvalidToken:          SeverityWarn,
+		Unauthenticated:       SeverityWarn,
+		BadAuthenticationData: SeverityWarn,
+		BadInputData:          SeverityWarn,
+		Internal:              SeverityError,
+		NotFound:              SeverityWarn,
+		BadChecksum:           SeverityWarn,
+		TooBig:                SeverityWarn,
+		PermissionDenied:      SeverityWarn,
+		AlreadyExists:         SeverityWarn,
+		RateLimited:           SeverityWarn,
+		Timeout:               SeverityError,
+		Canceled:              SeverityInfo,
+		Unavailable:           SeverityError,
+	}
+	for _, c := range AllCodes() {
+		w, ok := want[c]
+		if !ok {
+			t.Errorf("no expected severity for %v", c.Name())
+			continue
+		}
+		if got := c.Severity(); got != w {
+			t.Errorf("%v.Severity() = %v, want %v", c.Name(), got, w)
+		}
+	}
+	if got := Code(999).Severity(); got != SeverityError {
+		t.Errorf("Code(999).Severity() = %v, want error", got)
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	want := map[Category]string{
+		CategorySuccess: "OK",
+		CategoryAuth:    "AUTH",
+		CategoryClient:  "INPUT",
+		CategoryServer:  "ERR",
+	}
+	for _, c := range append(AllCodes(), Code(999)) {
+		got := c.Symbol()
+		if got != want[c.Category()] {
+			t.Errorf("Code(%d).Symbol() = %q, want %q", c, got, want[c.Category()])
+		}
+		for i := 0; i < len(got); i++ {
+			if got[i] > 127 {
+				t.Errorf("Code(%d).Symbol() = %q, want ASCII", c, got)
+			}
+		}
+	}
+}
+
+func TestIsAuth(t *testing.T) {
+	auth := NewCodeSet(InvalidToken, Unauthenticated, BadAuthenticationData, PermissionDenied)
+	for _, c := range append(AllCodes(), Code(999)) {
+		if got := c.IsAuth(); got != auth.Contains(c) {
+			t.Errorf("Code(%d).IsAuth() = %v, want %v", c, got, auth.Contains(c))
+		}
+	}
+}
+
+func TestTemplateName(t *testing.T) {
+	want := map[Category]string{
+		CategorySuccess: "success",
+		CategoryAuth:    "error_auth",
+		CategoryClient:  "error_input",
+		CategoryServer:  "error_server",
+	}
+	for _, c := range append(AllCodes(), Code(999)) {
+		if got := c.TemplateName(); got != want[c.Category()] {
+			t.Errorf("Code(%d).TemplateName() = %q, want %q", c, got, want[c.Category()])
+		}
+	}
+	if got := PermissionDenied.TemplateName(); got != "error_auth" {
+		t.Errorf("PermissionDenied.TemplateName() = %q, want error_auth", got)
+	}
+	if got := BadInputData.TemplateName(); got != "error_input" {
+		t.Errorf("BadInputData.TemplateName() = %q, want error_input", got)
+	}
+	if got := Unavailable.TemplateName(); got != "error_server" {
+		t.Errorf("Unavailable.TemplateName() = %q, want error_server", got)
+	}
+}
@@ -0,0 +1,233 @@
+package codes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// defaultMaxErrorBodySize is the default value of maxErrorBodySize.
+const defaultMaxErrorBodySize = 1 << 20
+
+// maxErrorBodySize is the maximum number of bytes read from an error body.
+// It is accessed atomically.
+var maxErrorBodySize int64 = defaultMaxErrorBodySize
+
+// SetMaxErrorBodySize sets the maximum size in bytes of the error bodies decoded by
+// CheckResponse, DecodeError and ErrFromResponse, which protects clients from
+// servers sending huge bodies. The default is 1 MiB; n <= 0 restores it.
+func SetMaxErrorBodySize(n int64) {
+	if n <= 0 {
+		n = defaultMaxErrorBodySize
+	}
+	atomic.StoreInt64(&maxErrorBodySize, n)
+}
+
+// readErrorBody reads an error body, up to the maximum error body size.
+// It returns an Internal Err if body cannot be read or is too large.
+func readErrorBody(body io.Reader) ([]byte, *Err) {
+	max := atomic.LoadInt64(&maxErrorBodySize)
+	data, err := ioutil.ReadAll(io.LimitReader(body, max+1))
+	if err != nil {
+		return data, NewErr(Internal, err.Error())
+	}
+	if int64(len(data)) > max {
+		return data, NewErrf(Internal, "error body exceeds %d bytes", max)
+	}
+	return data, nil
+}
+
+// hasNoBody reports whether r is known to have an empty body,
+// in which case it is not read at all.
+func hasNoBody(r *http.Response) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 ||
+		r.StatusCode == http.StatusNoContent
+}
+
+// envelopeErrJSON is the wire representation of an Err decoded from an upstream
+// envelope. The code is kept raw so an unknown code does not fail the decoding.
+type envelopeErrJSON struct {
+	errJSON
+	Code json.RawMessage `json:"code"`
+}
+
+// decodeEnvelope decodes the {"error":{...}} envelope in data, so errors proxied
+// through several services keep their original code and message. If the code of
+// the envelope is missing or not known by this service, e.g. a custom code of the
+// upstream, or if it is Success while status is not 2xx, the code is derived from
+// status. The message is sanitized like the ones given to New.
+// It returns nil if data is not an envelope.
+func decodeEnvelope(data []byte, status int) *Err {
+	var v struct {
+		Err *envelopeErrJSON `json:"error"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil || v.Err == nil {
+		return nil
+	}
+	var c Code
+	if len(v.Err.Code) == 0 || c.UnmarshalJSON(v.Err.Code) != nil || !c.Valid() ||
+		(c == Success && (status < 200 || status > 299)) {
+		c = CodeFromHTTPStatus(status)
+	}
+	e := New(c,
+		WithMessage(v.Err.Message),
+		WithReason(v.Err.Reason),
+		WithRetryAfter(seconds(v.Err.RetryAfter)))
+	e.Fields = v.Err.Fields
+	e.Details = v.Err.Details
+	return e
+}
+
+// CheckResponse checks the API response for errors, and returns them if present.
+// A response is considered an error if it has a status code outside the 200 range.
+// The body of successful responses, including 204 No Content, is not consumed.
+// For errors, the body is decoded with DecodeError and restored afterwards so it
+// can be read again. Empty bodies are not read and the error is derived from the status.
+func CheckResponse(r *http.Response) error {
+	if c := r.StatusCode; 200 <= c && c <= 299 {
+		return nil
+	}
+	if hasNoBody(r) {
+		return NewErrorResponse(r, NewErr(CodeFromHTTPStatus(r.StatusCode), ""))
+	}
+	data, e := readErrorBody(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	if e != nil {
+		return NewErrorResponse(r, e)
+	}
+	return NewErrorResponse(r, DecodeError(bytes.NewReader(data), r.StatusCode))
+}
+
+// DecodeError decodes the {"error":{...}} envelope read from body.
+// The code and message of the envelope are passed through, so errors keep their
+// original code across proxies; see decodeEnvelope for the codes that are not trusted.
+// If the body is empty or is not a valid envelope
+// (e.g. an HTML error page), an Err with the Code derived from status by
+// CodeFromHTTPStatus is returned.
+// Bodies larger than the maximum error body size yield an Internal Err.
+// For 204 No Content, body is not read and nil is returned.
+func DecodeError(body io.Reader, status int) *Err {
+	if status == http.StatusNoContent {
+		return nil
+	}
+	data, e := readErrorBody(body)
+	if e != nil {
+		return e
+	}
+	if e := decodeEnvelope(data, status); e != nil {
+		return e
+	}
+	return NewErr(CodeFromHTTPStatus(status), "")
+}
+
+// ErrFromResponse creates an Err that matches the response of an upstream service,
+// e.g. to proxy its error. The code is derived from the status with CodeFromHTTPStatus
+// and the message is the status text, unless the body holds an error envelope,
+// in which case its code and message are used. The body is restored afterwards.
+// Bodies larger than the maximum error body size yield an Internal Err.
+func ErrFromResponse(r *http.Response) *Err {
+	if hasNoBody(r) {
+		return NewErr(CodeFromHTTPStatus(r.StatusCode), http.StatusText(r.StatusCode))
+	}
+	data, e := readErrorBody(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	if e != nil {
+		return e
+	}
+	if e := decodeEnvelope(data, r.StatusCode); e != nil {
+		return e
+	}
+	return NewErr(CodeFromHTTPStatus(r.StatusCode), http.StatusText(r.StatusCode))
+}
+
+// Do sends req with client (http.DefaultClient if nil) and returns the response
+// wrapped with NewResponse. Responses with a status code outside the 200 range
+// are checked with CheckResponse, which yields an *ErrorResponse error.
+// The returned Response is also set in that case.
+func Do(client *http.Client, req *http.Request) (*Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	response := NewResponse(res)
+	if err := CheckResponse(res); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// RetryAfter returns how long the client should wait before retrying the request.
+// It reads the Retry-After header of the response, in either its delay-seconds
+// or its HTTP-date form, and falls back to the RetryAfter of the Err.
+// A date in the past yields zero. The boolean is false if no delay is known.
+func (r *ErrorResponse) RetryAfter() (time.Duration, bool) {
+	if r.Response != nil {
+		if v := r.Response.Header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return seconds(secs), true
+			}
+			if t, err := http.ParseTime(v); err == nil {
+				d := time.Until(t)
+				if d < 0 {
+					d = 0
+				}
+				return d, true
+			}
+		}
+	}
+	if r.Err != nil && r.Err.RetryAfter > 0 {
+		return r.Err.RetryAfter, true
+	}
+	return 0, false
+}
+
+// seconds converts a number of seconds received from a peer to a Duration,
+// clamping it to [0, math.MaxInt64] nanoseconds instead of overflowing.
+func seconds(secs int64) time.Duration {
+	switch {
+	case secs < 0:
+		return 0
+	case secs > int64(math.MaxInt64/time.Second):
+		return math.MaxInt64
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// errorRoundTripper is the http.RoundTripper returned by NewErrorRoundTripper.
+type errorRoundTripper struct {
+	next http.RoundTripper
+}
+
+// NewErrorRoundTripper returns an http.RoundTripper that executes requests with next
+// (http.DefaultTransport if nil) and turns responses with a status code >= 400 into
+// an *ErrorResponse error. The ErrorResponse keeps the response, with its body intact.
+// Other responses are returned unchanged.
+func NewErrorRoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &errorRoundTripper{next: next}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *errorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 400 {
+		return res, nil
+	}
+	return nil, CheckResponse(res)
+}
@@ -0,0 +1,333 @@
+package codes
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newResponse returns a response to a GET request with the given status and body.
+func newResponse(status int, body string) *http.Response {
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/files?token=secret", nil)
+	return &http.Response{
+		StatusCode:    status,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	res := newResponse(http.StatusOK, `{"ok":true}`)
+	if err := CheckResponse(res); err != nil {
+		t.Fatalf("CheckResponse(200) = %v, want nil", err)
+	}
+	if body, _ := ioutil.ReadAll(res.Body); string(body) != `{"ok":true}` {
+		t.Errorf("body after CheckResponse(200) = %q, want it untouched", body)
+	}
+
+	res = newResponse(http.StatusNotFound, `{"error":{"message":"no such file","code":"not_found"}}`)
+	err := CheckResponse(res)
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("CheckResponse(404) = %v, want an *ErrorResponse", err)
+	}
+	if errResp.Code != NotFound || errResp.Message != "no such file" {
+		t.Errorf("CheckResponse(404) = %v, want not_found with the message of the body", err)
+	}
+	if errResp.Response != res {
+		t.Error("ErrorResponse.Response is not the checked response")
+	}
+	if body, _ := ioutil.ReadAll(res.Body); !strings.Contains(string(body), "no such file") {
+		t.Errorf("body after CheckResponse(404) = %q, want it restored", body)
+	}
+
+	err = CheckResponse(newResponse(http.StatusInternalServerError, "<html>oops</html>"))
+	if got := CodeFromError(err); got != Internal {
+		t.Errorf("CheckResponse with a garbage body = %v, want internal", got.Name())
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		code    Code
+		message string
+	}{
+		{"envelope", `{"error":{"message":"stale","code":"bad_checksum"}}`, 412, BadChecksum, "stale"},
+		{"legacy numeric code", `{"error":{"message":"bad","code":4}}`, 400, BadInputData, "bad"},
+		{"empty body", "", 503, Unavailable, Unavailable.String()},
+		{"html page", "<html><body>Bad Gateway</body></html>", 502, Internal, Internal.String()},
+		{"json without envelope", `{"detail":"nope"}`, 404, NotFound, NotFound.String()},
+	}
+	for _, tt := range tests {
+		e := DecodeError(strings.NewReader(tt.body), tt.status)
+		if e == nil {
+			t.Errorf("%s: DecodeError = nil", tt.name)
+			continue
+		}
+		if e.Code != tt.code || e.Message != tt.message {
+			t.Errorf("%s: DecodeError = %v, want %d: %s", tt.name, e, tt.code, tt.message)
+		}
+	}
+}
+
+func TestDecodeErrorNoContent(t *testing.T) {
+	if e := DecodeError(strings.NewReader("garbage"), http.StatusNoContent); e != nil {
+		t.Errorf("DecodeError(204) = %v, want nil", e)
+	}
+}
+
+func TestErrorResponseRetryAfter(t *testing.T) {
+	res := newResponse(http.StatusTooManyRequests, "")
+	errResp := NewErrorResponse(res, NewErr(RateLimited, ""))
+	if d, ok := errResp.RetryAfter(); ok {
+		t.Errorf("RetryAfter() without header = %v, true, want false", d)
+	}
+
+	res.Header.Set("Retry-After", "120")
+	if d, ok := errResp.RetryAfter(); !ok || d != 2*time.Minute {
+		t.Errorf("RetryAfter() with seconds = %v, %v, want 2m0s, true", d, ok)
+	}
+
+	for _, v := range []string{"9223372037", "9223372036854775807"} {
+		res.Header.Set("Retry-After", v)
+		if d, ok := errResp.RetryAfter(); !ok || d != math.MaxInt64 {
+			t.Errorf("RetryAfter() with %s seconds = %v, %v, want the largest Duration, true", v, d, ok)
+		}
+	}
+	res.Header.Set("Retry-After", "-5")
+	if d, ok := errResp.RetryAfter(); !ok || d != 0 {
+		t.Errorf("RetryAfter() with negative seconds = %v, %v, want 0, true", d, ok)
+	}
+
+	res.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if d, ok := errResp.RetryAfter(); !ok || d < 59*time.Minute || d > time.Hour {
+		t.Errorf("RetryAfter() with a future date = %v, %v, want about 1h, true", d, ok)
+	}
+
+	res.Header.Set("Retry-After", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	if d, ok := errResp.RetryAfter(); !ok || d != 0 {
+		t.Errorf("RetryAfter() with a past date = %v, %v, want 0, true", d, ok)
+	}
+
+	errResp = NewErrorResponse(nil, NewErr(RateLimited, ""))
+	errResp.Err.RetryAfter = 5 * time.Second
+	if d, ok := errResp.RetryAfter(); !ok || d != 5*time.Second {
+		t.Errorf("RetryAfter() from the Err = %v, %v, want 5s, true", d, ok)
+	}
+}
+
+// newErrorServer returns a server that replies to /ok with 200 and to any
+// other path with the envelope of a NotFound error.
+func newErrorServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			w.Write([]byte("hello"))
+			return
+		}
+		WriteError(w, r, NewErr(NotFound, "no such file"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestErrorRoundTripper(t *testing.T) {
+	srv := newErrorServer(t)
+	client := &http.Client{Transport: NewErrorRoundTripper(nil)}
+
+	res, err := client.Get(srv.URL + "/ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("success = %d %q, want it unchanged", res.StatusCode, body)
+	}
+
+	_, err = client.Get(srv.URL + "/missing")
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("error = %v, want an *ErrorResponse", err)
+	}
+	if errResp.Code != NotFound || errResp.Message != "no such file" {
+		t.Errorf("ErrorResponse = %v, want the decoded envelope", errResp)
+	}
+	body, _ = ioutil.ReadAll(errResp.Response.Body)
+	if !strings.Contains(string(body), "no such file") {
+		t.Errorf("body = %q, want it intact", body)
+	}
+}
+
+func TestErrFromResponse(t *testing.T) {
+	res := newResponse(http.StatusServiceUnavailable, "")
+	res.Body = http.NoBody
+	e := ErrFromResponse(res)
+	if e.Code != Unavailable || e.Message != "Service Unavailable" {
+		t.Errorf("ErrFromResponse(503) = %v, want unavailable with the status text", e)
+	}
+
+	res = newResponse(http.StatusBadRequest, `{"error":{"message":"missing name","code":"bad_input_data","reason":"input.missing_field"}}`)
+	e = ErrFromResponse(res)
+	if e.Code != BadInputData || e.Message != "missing name" || e.Reason != "input.missing_field" {
+		t.Errorf("ErrFromResponse(400) = %v, want the envelope", e)
+	}
+	if body, _ := ioutil.ReadAll(res.Body); !strings.Contains(string(body), "missing name") {
+		t.Errorf("body = %q, want it restored", body)
+	}
+
+	e = ErrFromResponse(newResponse(http.StatusNotFound, "not json"))
+	if e.Code != NotFound || e.Message != "Not Found" {
+		t.Errorf("ErrFromResponse(404) = %v, want not_found with the status text", e)
+	}
+}
+
+func TestMaxErrorBodySize(t *testing.T) {
+	SetMaxErrorBodySize(64)
+	defer SetMaxErrorBodySize(0)
+
+	body := `{"error":{"message":"small","code":"not_found"}}`
+	if e := DecodeError(strings.NewReader(body), 404); e.Code != NotFound || e.Message != "small" {
+		t.Errorf("DecodeError under the limit = %v, want the envelope", e)
+	}
+
+	big := `{"error":{"message":"` + strings.Repeat("x", 100) + `","code":"not_found"}}`
+	if e := DecodeError(strings.NewReader(big), 404); e.Code != Internal || !strings.Contains(e.Message, "64 bytes") {
+		t.Errorf("DecodeError over the limit = %v, want an Internal Err", e)
+	}
+	if err := CheckResponse(newResponse(404, big)); CodeFromError(err) != Internal {
+		t.Errorf("CheckResponse over the limit = %v, want an Internal Err", err)
+	}
+	if e := ErrFromResponse(newResponse(404, big)); e.Code != Internal {
+		t.Errorf("ErrFromResponse over the limit = %v, want an Internal Err", e)
+	}
+}
+
+func TestDo(t *testing.T) {
+	srv := newErrorServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ok", nil)
+	res, err := Do(nil, req)
+	if err != nil {
+		t.Fatalf("Do(/ok) = %v", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !res.IsSuccess() || string(body) != "hello" {
+		t.Errorf("Do(/ok) = %d %q, want 200 hello", res.StatusCode, body)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/missing", nil)
+	res, err = Do(srv.Client(), req)
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Code != NotFound {
+		t.Fatalf("Do(/missing) = %v, want a not_found *ErrorResponse", err)
+	}
+	if res == nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("Do(/missing) response = %v, want the 404 response", res)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://127.0.0.1:1/", nil)
+	if res, err := Do(nil, req); err == nil || res != nil {
+		t.Errorf("Do(unreachable) = %v, %v, want nil and an error", res, err)
+	}
+}
+
+// unreadBody is a response body that records whether it was read.
+type unreadBody struct {
+	read bool
+}
+
+func (b *unreadBody) Read(p []byte) (int, error) {
+	b.read = true
+	return 0, errors.New("unexpected read")
+}
+
+func (b *unreadBody) Close() error { return nil }
+
+func TestCheckResponseEmptyBodies(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNoContent} {
+		body := &unreadBody{}
+		res := &http.Response{StatusCode: status, Header: http.Header{}, Body: body}
+		if err := CheckResponse(res); err != nil {
+			t.Errorf("CheckResponse(%d) = %v, want nil", status, err)
+		}
+		if body.read {
+			t.Errorf("CheckResponse(%d) read the body", status)
+		}
+	}
+
+	body := &unreadBody{}
+	res := &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: body, ContentLength: 0}
+	if err := CheckResponse(res); CodeFromError(err) != NotFound || body.read {
+		t.Errorf("CheckResponse(404 with no content) = %v, read %v, want not_found without reading", err, body.read)
+	}
+}
+
+func TestDecodeErrorUntrustedEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		code    Code
+		message string
+	}{
+		{"missing code", `{"error":{"message":"nope"}}`, 404, NotFound, "nope"},
+		{"unknown name", `{"error":{"message":"quota","code":"test_never_registered"}}`, 429, RateLimited, "quota"},
+		{"unknown number", `{"error":{"message":"custom","code":987654}}`, 503, Unavailable, "custom"},
+		{"invalid code", `{"error":{"message":"odd","code":{"x":1}}}`, 400, CodeFromHTTPStatus(400), "odd"},
+		{"success on error status", `{"error":{"message":"fine","code":"success"}}`, 500, Internal, "fine"},
+		{"invalid UTF-8", "{\"error\":{\"message\":\"bad \xff\",\"code\":\"internal\"}}", 500, Internal, "bad �"},
+	}
+	for _, tt := range tests {
+		e := DecodeError(strings.NewReader(tt.body), tt.status)
+		if e == nil {
+			t.Errorf("%s: DecodeError = nil", tt.name)
+			continue
+		}
+		if e.Code != tt.code || e.Message != tt.message {
+			t.Errorf("%s: DecodeError = %v, want %d: %s", tt.name, e, tt.code, tt.message)
+		}
+	}
+}
+
+func TestDecodeErrorThroughProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, New(Unavailable, WithMessage("replica lagging"), WithReason("replica_lag")))
+	}))
+	defer upstream.Close()
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := http.Get(upstream.URL)
+		if err != nil {
+			WriteError(w, r, Wrap(Internal, err))
+			return
+		}
+		defer res.Body.Close()
+		WriteError(w, r, ErrFromResponse(res))
+	}))
+	defer gateway.Close()
+
+	res, err := http.Get(gateway.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var errResp *ErrorResponse
+	if !errors.As(CheckResponse(res), &errResp) {
+		t.Fatalf("CheckResponse of the gateway did not return an ErrorResponse")
+	}
+	e := errResp.Err
+	if e.Code != Unavailable || e.Message != "replica lagging" || e.Reason != "replica_lag" {
+		t.Errorf("error through the gateway = %+v, want the upstream unavailable error", e)
+	}
+}
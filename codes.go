@@ -1,9 +1,17 @@
 package codes
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // A Code is an unsigned 32-bit error code.
@@ -38,37 +46,397 @@ const (
 
 	// TooBig is returned when something is too big to be processed.
 	TooBig
+
+	// PermissionDenied is returned when the caller is authenticated but not
+	// allowed to perform the operation.
+	PermissionDenied
+
+	// AlreadyExists is returned when the resource to be created already exists.
+	AlreadyExists
+
+	// RateLimited is returned when the caller has exceeded its request quota.
+	RateLimited
+
+	// Timeout is returned when an upstream call did not complete in time.
+	Timeout
+
+	// Canceled is returned when the operation was canceled by the caller,
+	// e.g. because the client closed the connection.
+	Canceled
+
+	// Unavailable is returned when the service or one of its dependencies
+	// is temporarily unavailable.
+	Unavailable
 )
 
+// names holds the stable machine-readable identifier of every defined Code.
+// These identifiers are part of the wire format and must never change.
+var names = map[Code]string{
+	Success:               "success",
+	InvalidToken:          "invalid_token",
+	Unauthenticated:       "unauthenticated",
+	BadAuthenticationData: "bad_authentication_data",
+	BadInputData:          "bad_input_data",
+	Internal:              "internal",
+	NotFound:              "not_found",
+	BadChecksum:           "bad_checksum",
+	TooBig:                "too_big",
+	PermissionDenied:      "permission_denied",
+	AlreadyExists:         "already_exists",
+	RateLimited:           "rate_limited",
+	Timeout:               "timeout",
+	Canceled:              "canceled",
+	Unavailable:           "unavailable",
+}
+
+// AllCodes returns all the codes defined by this package in iota order.
+// Codes added with Register are not included.
+// The returned slice is a copy and can be freely modified.
+func AllCodes() []Code {
+	all := make([]Code, 0, len(names))
+	for c := Success; int(c) < len(names); c++ {
+		all = append(all, c)
+	}
+	return all
+}
+
+// deprecated holds the codes that are kept for backward compatibility
+// but should not be used by new code. They are never removed from the
+// iota block so the values of the other codes do not change.
+var deprecated = map[Code]bool{}
+
+// Deprecated reports whether the Code is deprecated and should not be used by new code.
+func (c Code) Deprecated() bool {
+	return deprecated[c]
+}
+
+// DeprecatedCodes returns the deprecated codes in iota order.
+func DeprecatedCodes() []Code {
+	var codes []Code
+	for _, c := range AllCodes() {
+		if c.Deprecated() {
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
+
+// Name returns the stable snake-case identifier of the Code used on the wire
+// (e.g. "bad_input_data"), or "unknown" for codes that are not defined.
+func (c Code) Name() string {
+	if name, ok := codeName(c); ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Valid reports whether c is one of the defined or registered codes.
+func (c Code) Valid() bool {
+	_, ok := codeName(c)
+	return ok
+}
+
+// strict is 1 when undefined codes make the package panic.
+// It is accessed atomically.
+var strict int32
+
+// SetStrict sets whether New, NewErr and Code.String panic when given an undefined
+// Code, instead of falling back to Internal and "unknown error". It is intended
+// for development and test builds, to catch misuse early; production code should
+// keep the default lenient mode. Only those functions panic: an Err holding an
+// undefined code, e.g. one decoded from a remote peer, can still be encoded,
+// localized and written. The setting is global.
+func SetStrict(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&strict, v)
+}
+
+// checkStrict panics if strict mode is enabled.
+func checkStrict(c Code) {
+	if atomic.LoadInt32(&strict) == 1 {
+		panic(fmt.Sprintf("codes: undefined code %d", c))
+	}
+}
+
+// ParseCode returns the Code identified by s, which must be one of the
+// identifiers returned by Code.Name (e.g. "invalid_token").
+// The comparison is case-insensitive and ignores surrounding whitespace.
+func ParseCode(s string) (Code, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for c, name := range names {
+		if name == s {
+			return c, nil
+		}
+	}
+	if c, ok := lookupCustomName(s); ok {
+		return c, nil
+	}
+	return Internal, fmt.Errorf("codes: unknown code %q", s)
+}
+
 // String returns a string representation of the Code
 func (c Code) String() string {
+	msg, ok := c.message()
+	if !ok {
+		checkStrict(c)
+	}
+	return msg
+}
+
+// message returns the default message of the Code, and false with
+// a neutral fallback if the Code is not defined. Unlike String, it never
+// panics, so it can be used on codes received from remote peers.
+func (c Code) message() (string, bool) {
 	switch c {
+	case Success:
+		return "success", true
 	case InvalidToken:
-		return "invalid or expired token"
+		return "invalid or expired token", true
 	case Unauthenticated:
-		return "unauthenticated request"
+		return "unauthenticated request", true
 	case BadAuthenticationData:
-		return "bad authentication data"
+		return "bad authentication data", true
+	case BadInputData:
+		return "bad input data", true
+	case Internal:
+		return "internal error. Please submit a query to the support team", true
+	case NotFound:
+		return "resource not found", true
+	case BadChecksum:
+		return "checksums differ", true
+	case TooBig:
+		return "too big", true
+	case PermissionDenied:
+		return "permission denied", true
+	case AlreadyExists:
+		return "resource already exists", true
+	case RateLimited:
+		return "rate limit exceeded", true
+	case Timeout:
+		return "request timed out", true
+	case Canceled:
+		return "request canceled", true
+	case Unavailable:
+		return "service temporarily unavailable", true
+	default:
+		if cc, ok := lookupCustom(c); ok {
+			return cc.message, true
+		}
+		return "unknown error", false
+	}
+}
+
+// statusClientClosedRequest is the non-standard status used by nginx
+// when the client closes the connection before the response is sent.
+const statusClientClosedRequest = 499
+
+// statusOverrides holds the HTTP statuses set with SetHTTPStatus.
+var statusOverrides = struct {
+	sync.RWMutex
+	statuses map[Code]int
+}{statuses: map[Code]int{}}
+
+// SetHTTPStatus overrides the HTTP status returned by c.HTTPStatus(), e.g. to
+// report BadInputData as 422 Unprocessable Entity. CodeFromHTTPStatus maps the
+// status back to c. A status of 0 restores the default mapping. It returns an
+// error if status is not a valid HTTP status, from 100 to 999, since it could not
+// be written. The setting is global and is meant to be applied at startup.
+func SetHTTPStatus(c Code, status int) error {
+	if status != 0 && (status < 100 || status > 999) {
+		return fmt.Errorf("codes: invalid HTTP status %d", status)
+	}
+	statusOverrides.Lock()
+	defer statusOverrides.Unlock()
+	if status == 0 {
+		delete(statusOverrides.statuses, c)
+		return nil
+	}
+	statusOverrides.statuses[c] = status
+	return nil
+}
+
+// HTTPStatus returns the HTTP status code that best describes the Code.
+// Unknown codes are reported as http.StatusInternalServerError.
+// The default mapping can be changed with SetHTTPStatus.
+func (c Code) HTTPStatus() int {
+	statusOverrides.RLock()
+	status, ok := statusOverrides.statuses[c]
+	statusOverrides.RUnlock()
+	if ok {
+		return status
+	}
+	switch c {
+	case Success:
+		return http.StatusOK
+	case InvalidToken, Unauthenticated:
+		return http.StatusUnauthorized
+	case BadAuthenticationData, PermissionDenied:
+		return http.StatusForbidden
 	case BadInputData:
-		return "bad input data"
+		return http.StatusBadRequest
 	case Internal:
-		return "internal error. Please submit a query to the support team"
+		return http.StatusInternalServerError
 	case NotFound:
-		return "not found"
+		return http.StatusNotFound
 	case BadChecksum:
-		return "checksums differ"
+		return http.StatusPreconditionFailed
 	case TooBig:
-		return "too big"
+		return http.StatusRequestEntityTooLarge
+	case AlreadyExists:
+		return http.StatusConflict
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case Timeout:
+		return http.StatusGatewayTimeout
+	case Canceled:
+		return statusClientClosedRequest
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// overriddenCode returns the lowest code whose status has been set to status
+// with SetHTTPStatus.
+func overriddenCode(status int) (Code, bool) {
+	statusOverrides.RLock()
+	defer statusOverrides.RUnlock()
+	var code Code
+	found := false
+	for c, s := range statusOverrides.statuses {
+		if s == status && (!found || c < code) {
+			code, found = c, true
+		}
+	}
+	return code, found
+}
+
+// StatusClass returns the class of the HTTP status of the Code, that is,
+// its hundreds digit (e.g. 4 for BadInputData or 5 for Internal).
+func (c Code) StatusClass() int {
+	return c.HTTPStatus() / 100
+}
+
+// StatusText returns the reason phrase of the HTTP status of the Code,
+// as given by http.StatusText.
+func (c Code) StatusText() string {
+	status := c.HTTPStatus()
+	if status == statusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+// Retryable reports whether an operation that failed with the Code may succeed
+// if retried without changes. Only transient server-side failures (Internal,
+// RateLimited, Timeout and Unavailable) are retryable; client errors such as
+// BadInputData or Unauthenticated will fail again until the request is fixed.
+func (c Code) Retryable() bool {
+	switch c {
+	case Internal, RateLimited, Timeout, Unavailable:
+		return true
 	default:
-		return "FIXME: this should be a helpful message"
+		return false
+	}
+}
+
+// CodeFromHTTPStatus returns the Code that corresponds to an HTTP status code.
+// It is the approximate inverse of Code.HTTPStatus: the mapping is lossy because
+// several codes share the same status (e.g. InvalidToken and Unauthenticated
+// are both reported as 401, which maps back to Unauthenticated).
+// Any 2xx status maps to Success and unmatched statuses map to Internal.
+// Statuses set with SetHTTPStatus map to their code; if several codes share
+// the same overridden status, the lowest one is returned.
+func CodeFromHTTPStatus(status int) Code {
+	if c, ok := overriddenCode(status); ok {
+		return c
+	}
+	switch {
+	case status >= 200 && status <= 299:
+		return Success
+	case status == http.StatusUnauthorized:
+		return Unauthenticated
+	case status == http.StatusForbidden:
+		return BadAuthenticationData
+	case status == http.StatusBadRequest:
+		return BadInputData
+	case status == http.StatusNotFound:
+		return NotFound
+	case status == http.StatusPreconditionFailed:
+		return BadChecksum
+	case status == http.StatusRequestEntityTooLarge:
+		return TooBig
+	case status == http.StatusConflict:
+		return AlreadyExists
+	case status == http.StatusTooManyRequests:
+		return RateLimited
+	case status == http.StatusServiceUnavailable:
+		return Unavailable
+	case status == http.StatusGatewayTimeout:
+		return Timeout
+	case status == statusClientClosedRequest:
+		return Canceled
+	default:
+		return Internal
 	}
 }
 
 // Response is a ClawIO API response.  This wraps the standard http.Response
-// returned from ClawIO and provides convenient access to future things like
+// returned from ClawIO and provides convenient access to things like
 // pagination links.
 type Response struct {
 	*http.Response
+
+	// These fields provide the page values for paginating through a set of
+	// results. Any or all of these may be set to the zero value for
+	// responses that are not part of a paginated set, or for which there
+	// are no additional pages.
+
+	NextPage  int
+	PrevPage  int
+	FirstPage int
+	LastPage  int
+
+	Rate
+}
+
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+)
+
+// Rate represents the rate limit for the current client.
+type Rate struct {
+	// The number of requests the client is limited to in the current window.
+	Limit int `json:"limit"`
+
+	// The number of requests the client can still make in the current window.
+	Remaining int `json:"remaining"`
+
+	// The time at which the current rate limit will reset.
+	Reset time.Time `json:"reset"`
+}
+
+// parseRate parses the rate related headers.
+func parseRate(r *http.Response) Rate {
+	var rate Rate
+	if limit := r.Header.Get(headerRateLimit); limit != "" {
+		rate.Limit, _ = strconv.Atoi(limit)
+	}
+	if remaining := r.Header.Get(headerRateRemaining); remaining != "" {
+		rate.Remaining, _ = strconv.Atoi(remaining)
+	}
+	if reset := r.Header.Get(headerRateReset); reset != "" {
+		if v, _ := strconv.ParseInt(reset, 10, 64); v != 0 {
+			rate.Reset = time.Unix(v, 0)
+		}
+	}
+	return rate
 }
 
 func (r *Response) String() string {
@@ -78,16 +446,102 @@ func (r *Response) String() string {
 
 }
 
+// IsSuccess reports whether the response has a 2xx status code.
+func (r *Response) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode <= 299
+}
+
+// IsClientError reports whether the response has a 4xx status code.
+func (r *Response) IsClientError() bool {
+	return r.StatusCode >= 400 && r.StatusCode <= 499
+}
+
+// IsServerError reports whether the response has a 5xx status code.
+func (r *Response) IsServerError() bool {
+	return r.StatusCode >= 500 && r.StatusCode <= 599
+}
+
+// IsRateLimited reports whether the response has a 429 status code.
+func (r *Response) IsRateLimited() bool {
+	return r.StatusCode == http.StatusTooManyRequests
+}
+
 // NewResponse creates a new Response for the provided http.Response.
 func NewResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
+	response.populatePageValues()
+	response.Rate = parseRate(r)
 	return response
 }
 
+// populatePageValues parses the HTTP Link response headers (RFC 5988) and
+// populates the various pagination link values in the Response.
+func (r *Response) populatePageValues() {
+	links := r.Response.Header["Link"]
+	for _, link := range strings.Split(strings.Join(links, ","), ",") {
+		segments := strings.Split(strings.TrimSpace(link), ";")
+
+		// link must at least have href and rel
+		if len(segments) < 2 {
+			continue
+		}
+
+		// ensure href is properly formatted
+		if !strings.HasPrefix(segments[0], "<") || !strings.HasSuffix(segments[0], ">") {
+			continue
+		}
+
+		// try to pull out page parameter
+		u, err := url.Parse(segments[0][1 : len(segments[0])-1])
+		if err != nil {
+			continue
+		}
+		page := u.Query().Get("page")
+		if page == "" {
+			continue
+		}
+		n, err := strconv.Atoi(page)
+		if err != nil {
+			continue
+		}
+
+		for _, segment := range segments[1:] {
+			switch strings.TrimSpace(segment) {
+			case `rel="next"`:
+				r.NextPage = n
+			case `rel="prev"`:
+				r.PrevPage = n
+			case `rel="first"`:
+				r.FirstPage = n
+			case `rel="last"`:
+				r.LastPage = n
+			}
+		}
+	}
+}
+
 // An ErrorResponse reports one or more errors caused by an API request.
 type ErrorResponse struct {
 	Response *http.Response `json:"-"` // HTTP response that caused this error
 	*Err     `json:"error"` // more detail on individual errors
+
+	// requestID is the request ID decoded from the envelope, if any.
+	requestID string
+}
+
+// headerRequestID is the header used to correlate requests with server logs.
+const headerRequestID = "X-Request-Id"
+
+// RequestID returns the ID of the request that caused the error, taken from
+// the X-Request-Id header of the response or, if absent, from the decoded
+// envelope. It returns an empty string if the ID is unknown.
+func (r *ErrorResponse) RequestID() string {
+	if r.Response != nil {
+		if id := r.Response.Header.Get(headerRequestID); id != "" {
+			return id
+		}
+	}
+	return r.requestID
 }
 
 // NewErrorResponse wraps a Response with an error.
@@ -96,42 +550,253 @@ func NewErrorResponse(res *http.Response, e *Err) *ErrorResponse {
 	return response
 }
 
+// Error implements the error interface.
+// It degrades to the Err details when there is no response or request,
+// e.g. when the transport failed before getting a response.
 func (r *ErrorResponse) Error() string {
+	detail := Internal.String()
+	if r.Err != nil {
+		detail = r.Err.Error()
+	}
+	if id := r.RequestID(); id != "" {
+		detail = fmt.Sprintf("%s, request_id=%s", detail, id)
+	}
+	if r.Response == nil {
+		return detail
+	}
+	if r.Response.Request == nil {
+		return fmt.Sprintf("%d (%s)", r.Response.StatusCode, detail)
+	}
 	return fmt.Sprintf("%v %v: %d (%s)",
 		r.Response.Request.Method, sanitizeURL(r.Response.Request.URL),
-		r.Response.StatusCode, r.Err.Error())
+		r.Response.StatusCode, detail)
+}
+
+// Unwrap returns the Err carried by the ErrorResponse, so errors.As can find it.
+func (r *ErrorResponse) Unwrap() error {
+	if r.Err == nil {
+		return nil
+	}
+	return r.Err
+}
+
+// CodeFromError returns the Code of the first *Err found in the chain of err.
+// It returns Success for a nil error and Internal when the chain has no *Err.
+func CodeFromError(err error) Code {
+	if err == nil {
+		return Success
+	}
+	var e *Err
+	if errors.As(err, &e) && e != nil {
+		return e.Code
+	}
+	return Internal
+}
+
+// SameCode reports whether a and b carry the same Code, as returned by
+// CodeFromError, regardless of their messages.
+func SameCode(a, b error) bool {
+	return CodeFromError(a) == CodeFromError(b)
 }
 
 // An Err reports more details on an individual error in an ErrorResponse.
 type Err struct {
 	Message string `json:"message"`
 	Code    Code   `json:"code"`
+
+	// RetryAfter is how long the client should wait before retrying.
+	// It is encoded in seconds and omitted when zero.
+	RetryAfter time.Duration `json:"-"`
+
+	// Fields holds the details of the invalid fields of a request.
+	Fields []FieldError `json:"fields,omitempty"`
+
+	// Reason is an optional machine-readable sub-code that refines Code,
+	// namespaced with dots (e.g. "input.missing_field").
+	Reason string `json:"reason,omitempty"`
+
+	// Details holds arbitrary structured context about the error, such as
+	// the current version of a conflicting resource. It is omitted when empty.
+	Details json.RawMessage `json:"details,omitempty"`
+
+	// cause is the underlying error, if any. It is never serialized
+	// to avoid leaking internal details to clients.
+	cause error
+
+	// stack holds the program counters of the call stack where the Err
+	// was created, if it was created with NewErrWithStack.
+	stack []uintptr
+}
+
+// A FieldError reports why an individual request field is not valid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 // Error() implements the Error interface.
 func (e *Err) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%d: %s: %v", e.Code, e.Message, e.cause)
+	}
 	return fmt.Sprintf("%d: %s", e.Code, e.Message)
 }
 
+// Unwrap returns the underlying cause of the Err, if any.
+func (e *Err) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *Err with the same Code, which lets
+// errors.Is match an Err against the sentinel values below.
+// A nil Err, e.g. the one of an ErrorResponse without details, matches nothing.
+func (e *Err) Is(target error) bool {
+	t, ok := target.(*Err)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Temporary reports whether the error is temporary. It is an alias of
+// e.Code.Retryable() so an Err satisfies the Temporary method of net.Error
+// expected by some retry libraries. A nil Err is not temporary.
+func (e *Err) Temporary() bool {
+	return e != nil && e.Code.Retryable()
+}
+
+// Equal reports whether e and other have the same Code, Message, Reason and Fields.
+// The cause and the stack are intentionally ignored, so Equal can be used
+// with cmp.Comparer((*Err).Equal) to compare errors in tests.
+func (e *Err) Equal(other *Err) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	if e.Code != other.Code || e.Message != other.Message || e.Reason != other.Reason ||
+		len(e.Fields) != len(other.Fields) {
+		return false
+	}
+	for i := range e.Fields {
+		if e.Fields[i] != other.Fields[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Sentinel errors for every Code, to be used with errors.Is.
+var (
+	ErrInvalidToken          = NewErr(InvalidToken, "")
+	ErrUnauthenticated       = NewErr(Unauthenticated, "")
+	ErrBadAuthenticationData = NewErr(BadAuthenticationData, "")
+	ErrBadInput              = NewErr(BadInputData, "")
+	ErrInternal              = NewErr(Internal, "")
+	ErrNotFound              = NewErr(NotFound, "")
+	ErrBadChecksum           = NewErr(BadChecksum, "")
+	ErrTooBig                = NewErr(TooBig, "")
+	ErrPermissionDenied      = NewErr(PermissionDenied, "")
+	ErrAlreadyExists         = NewErr(AlreadyExists, "")
+	ErrRateLimited           = NewErr(RateLimited, "")
+	ErrTimeout               = NewErr(Timeout, "")
+	ErrCanceled              = NewErr(Canceled, "")
+	ErrUnavailable           = NewErr(Unavailable, "")
+)
+
+// WithMessage returns a copy of the Err with the given message and the same Code.
+// The receiver is not modified, so it is safe to use on the sentinel values.
+// The message is sanitized like the ones given to New.
+func (e *Err) WithMessage(msg string) *Err {
+	c := *e
+	c.Message = sanitizeMessage(msg)
+	return &c
+}
+
+// WithMessagef works like WithMessage but formats the message according
+// to a format specifier.
+func (e *Err) WithMessagef(format string, args ...interface{}) *Err {
+	return e.WithMessage(fmt.Sprintf(format, args...))
+}
+
+// WithField returns a copy of the Err with a FieldError appended to its Fields.
+// The receiver is not modified, so calls can be chained from the sentinel values.
+func (e *Err) WithField(field, msg string) *Err {
+	c := *e
+	c.Fields = make([]FieldError, len(e.Fields), len(e.Fields)+1)
+	copy(c.Fields, e.Fields)
+	c.Fields = append(c.Fields, FieldError{Field: field, Message: msg})
+	return &c
+}
+
+// WithDetails returns a copy of the Err with its Details set to the JSON encoding of v.
+// The receiver is not modified, so it is safe to use on the sentinel values.
+func (e *Err) WithDetails(v interface{}) (*Err, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	c := *e
+	c.Details = data
+	return &c, nil
+}
+
+// SetDetails sets the Details of the Err to the JSON encoding of v.
+// It modifies the receiver, so it must not be used on the sentinel values
+// or on any Err shared between goroutines; use WithDetails instead.
+func (e *Err) SetDetails(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.Details = data
+	return nil
+}
+
 // NewErr is a usefull function to create Errs with the corresponding Code message.
 // If no message is passed, the default code message will be used.
+// Codes that are not valid are normalized to Internal.
 func NewErr(c Code, msg string) *Err {
-	if msg == "" {
-		msg = c.String()
-	}
-	return &Err{msg, c}
+	return New(c, WithMessage(msg))
 }
 
-// sanitizeURL redacts the token parameter from the URL which may be
-// exposed to the user, specifically in the ErrorResponse error message.
-func sanitizeURL(uri *url.URL) *url.URL {
-	if uri == nil {
-		return nil
+// NewErrf works like NewErr but formats the message according to a format specifier.
+// If format is empty, the default code message will be used.
+func NewErrf(c Code, format string, args ...interface{}) *Err {
+	if format == "" {
+		return NewErr(c, "")
 	}
-	params := uri.Query()
-	if len(params.Get("token")) > 0 {
-		params.Set("token", "REDACTED")
-		uri.RawQuery = params.Encode()
+	return NewErr(c, fmt.Sprintf(format, args...))
+}
+
+// NewErrWithReason works like NewErr but also sets the Reason of the Err.
+func NewErrWithReason(c Code, reason, msg string) *Err {
+	e := NewErr(c, msg)
+	e.Reason = reason
+	return e
+}
+
+// ValidationErr creates a BadInputData Err with one FieldError for each entry
+// of fieldMessages, which maps field names to messages. The fields are sorted
+// by name so the output is deterministic.
+func ValidationErr(fieldMessages map[string]string) *Err {
+	fields := make([]FieldError, 0, len(fieldMessages))
+	for field, msg := range fieldMessages {
+		fields = append(fields, FieldError{Field: field, Message: msg})
 	}
-	return uri
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return NewValidationErr(fields...)
+}
+
+// Wrap creates an Err with the default Code message that keeps cause as
+// its underlying error, so it can be inspected with errors.Unwrap and errors.As.
+func Wrap(c Code, cause error) *Err {
+	e := NewErr(c, "")
+	e.cause = cause
+	return e
+}
+
+// NewValidationErr creates a BadInputData Err with the details of the invalid fields.
+func NewValidationErr(fields ...FieldError) *Err {
+	e := NewErr(BadInputData, "one or more fields are not valid")
+	e.Fields = fields
+	return e
 }
@@ -0,0 +1,800 @@
+package codes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{Success, http.StatusOK},
+		{InvalidToken, http.StatusUnauthorized},
+		{Unauthenticated, http.StatusUnauthorized},
+		{BadAuthenticationData, http.StatusForbidden},
+		{BadInputData, http.StatusBadRequest},
+		{Internal, http.StatusInternalServerError},
+		{NotFound, http.StatusNotFound},
+		{BadChecksum, http.StatusPreconditionFailed},
+		{TooBig, http.StatusRequestEntityTooLarge},
+		{PermissionDenied, http.StatusForbidden},
+		{AlreadyExists, http.StatusConflict},
+		{RateLimited, http.StatusTooManyRequests},
+		{Timeout, http.StatusGatewayTimeout},
+		{Canceled, 499},
+		{Unavailable, http.StatusServiceUnavailable},
+		{Code(999), http.StatusInternalServerError},
+	}
+	if len(tests)-1 != len(AllCodes()) {
+		t.Fatalf("%d codes tested, want %d", len(tests)-1, len(AllCodes()))
+	}
+	for _, tt := range tests {
+		if got := tt.code.HTTPStatus(); got != tt.want {
+			t.Errorf("Code(%d).HTTPStatus() = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   Code
+	}{
+		{199, Internal},
+		{200, Success},
+		{204, Success},
+		{299, Success},
+		{300, Internal},
+		{400, BadInputData},
+		{401, Unauthenticated},
+		{403, BadAuthenticationData},
+		{404, NotFound},
+		{409, AlreadyExists},
+		{429, RateLimited},
+		{500, Internal},
+		{503, Unavailable},
+		{504, Timeout},
+		{599, Internal},
+	}
+	for _, tt := range tests {
+		if got := CodeFromHTTPStatus(tt.status); got != tt.want {
+			t.Errorf("CodeFromHTTPStatus(%d) = %v, want %v", tt.status, got.Name(), tt.want.Name())
+		}
+	}
+}
+
+func TestCodeFromHTTPStatusInverse(t *testing.T) {
+	for _, c := range AllCodes() {
+		status := c.HTTPStatus()
+		if got := CodeFromHTTPStatus(status); got.HTTPStatus() != status {
+			t.Errorf("CodeFromHTTPStatus(%d) = %v, which maps to %d", status, got.Name(), got.HTTPStatus())
+		}
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Code
+	}{
+		{"invalid_token", InvalidToken},
+		{"Unauthenticated", Unauthenticated},
+		{"BAD_INPUT_DATA", BadInputData},
+		{"  internal\t", Internal},
+		{"\nNot_Found ", NotFound},
+	}
+	for _, tt := range tests {
+		got, err := ParseCode(tt.in)
+		if err != nil {
+			t.Errorf("ParseCode(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCode(%q) = %v, want %v", tt.in, got.Name(), tt.want.Name())
+		}
+	}
+	for _, in := range []string{"", "nope", "bad input data", "5"} {
+		if got, err := ParseCode(in); err == nil {
+			t.Errorf("ParseCode(%q) = %v, want an error", in, got.Name())
+		}
+	}
+}
+
+func TestParseCodeName(t *testing.T) {
+	for _, c := range AllCodes() {
+		if got, err := ParseCode(c.Name()); err != nil || got != c {
+			t.Errorf("ParseCode(%q) = %v, %v, want %v", c.Name(), got.Name(), err, c.Name())
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	if NotFound != 6 {
+		t.Errorf("NotFound = %d, want 6", NotFound)
+	}
+	if got, want := NotFound.String(), "resource not found"; got != want {
+		t.Errorf("NotFound.String() = %q, want %q", got, want)
+	}
+}
+
+func TestPermissionDenied(t *testing.T) {
+	if PermissionDenied == BadAuthenticationData {
+		t.Fatal("PermissionDenied == BadAuthenticationData")
+	}
+	if PermissionDenied.Name() == BadAuthenticationData.Name() {
+		t.Errorf("PermissionDenied and BadAuthenticationData share the name %q", PermissionDenied.Name())
+	}
+	if got, want := PermissionDenied.String(), "permission denied"; got != want {
+		t.Errorf("PermissionDenied.String() = %q, want %q", got, want)
+	}
+	if got := PermissionDenied.HTTPStatus(); got != http.StatusForbidden {
+		t.Errorf("PermissionDenied.HTTPStatus() = %d, want %d", got, http.StatusForbidden)
+	}
+}
+
+func TestAlreadyExists(t *testing.T) {
+	if got, want := AlreadyExists.String(), "resource already exists"; got != want {
+		t.Errorf("AlreadyExists.String() = %q, want %q", got, want)
+	}
+	if got := AlreadyExists.HTTPStatus(); got != http.StatusConflict {
+		t.Errorf("AlreadyExists.HTTPStatus() = %d, want %d", got, http.StatusConflict)
+	}
+	if AlreadyExists.Retryable() {
+		t.Error("AlreadyExists.Retryable() = true, want false")
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	if got, want := RateLimited.String(), "rate limit exceeded"; got != want {
+		t.Errorf("RateLimited.String() = %q, want %q", got, want)
+	}
+	if got := RateLimited.HTTPStatus(); got != http.StatusTooManyRequests {
+		t.Errorf("RateLimited.HTTPStatus() = %d, want %d", got, http.StatusTooManyRequests)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	retryable := map[Code]bool{
+		Internal:    true,
+		RateLimited: true,
+		Timeout:     true,
+		Unavailable: true,
+	}
+	for _, c := range AllCodes() {
+		if got := c.Retryable(); got != retryable[c] {
+			t.Errorf("%v.Retryable() = %v, want %v", c.Name(), got, retryable[c])
+		}
+	}
+	if Code(999).Retryable() {
+		t.Error("Code(999).Retryable() = true, want false")
+	}
+}
+
+func TestNewResponsePagination(t *testing.T) {
+	r := &http.Response{Header: http.Header{}}
+	r.Header.Add("Link", `<https://api.example.com/files?page=3>; rel="next", `+
+		`<https://api.example.com/files?page=1>; rel="prev", `+
+		`<https://api.example.com/files?page=1>; rel="first"`)
+	r.Header.Add("Link", `<https://api.example.com/files?page=7>; rel="last"`)
+	res := NewResponse(r)
+	if res.NextPage != 3 || res.PrevPage != 1 || res.FirstPage != 1 || res.LastPage != 7 {
+		t.Errorf("pages = next %d, prev %d, first %d, last %d, want 3, 1, 1, 7",
+			res.NextPage, res.PrevPage, res.FirstPage, res.LastPage)
+	}
+}
+
+func TestNewResponsePaginationMissing(t *testing.T) {
+	r := &http.Response{Header: http.Header{}}
+	r.Header.Set("Link", `<https://api.example.com/files?page=2>; rel="next", `+
+		`<https://api.example.com/files>; rel="last", garbage`)
+	res := NewResponse(r)
+	if res.NextPage != 2 || res.PrevPage != 0 || res.FirstPage != 0 || res.LastPage != 0 {
+		t.Errorf("pages = next %d, prev %d, first %d, last %d, want 2, 0, 0, 0",
+			res.NextPage, res.PrevPage, res.FirstPage, res.LastPage)
+	}
+}
+
+func TestNewResponseRate(t *testing.T) {
+	r := &http.Response{Header: http.Header{}}
+	r.Header.Set("X-RateLimit-Limit", "60")
+	r.Header.Set("X-RateLimit-Remaining", "13")
+	r.Header.Set("X-RateLimit-Reset", "1372700873")
+	rate := NewResponse(r).Rate
+	if rate.Limit != 60 || rate.Remaining != 13 {
+		t.Errorf("Rate = %+v, want limit 60 and remaining 13", rate)
+	}
+	if want := time.Unix(1372700873, 0); !rate.Reset.Equal(want) {
+		t.Errorf("Rate.Reset = %v, want %v", rate.Reset, want)
+	}
+
+	rate = NewResponse(&http.Response{Header: http.Header{}}).Rate
+	if rate != (Rate{}) {
+		t.Errorf("Rate without headers = %+v, want the zero value", rate)
+	}
+}
+
+func TestErrIs(t *testing.T) {
+	err := fmt.Errorf("loading user: %w", NewErr(BadInputData, "bad id"))
+	if !errors.Is(err, ErrBadInput) {
+		t.Errorf("errors.Is(%v, ErrBadInput) = false, want true", err)
+	}
+	if errors.Is(err, ErrInternal) {
+		t.Errorf("errors.Is(%v, ErrInternal) = true, want false", err)
+	}
+	if errors.Is(errors.New("bad input data"), ErrBadInput) {
+		t.Error("errors.Is(plain error, ErrBadInput) = true, want false")
+	}
+}
+
+func TestErrIsNil(t *testing.T) {
+	for _, errResp := range []*ErrorResponse{NewErrorResponse(nil, nil), {}} {
+		if errors.Is(errResp, ErrInternal) {
+			t.Error("errors.Is(ErrorResponse without Err, ErrInternal) = true, want false")
+		}
+	}
+	var e *Err
+	if e.Is(ErrInternal) {
+		t.Error("(*Err)(nil).Is(ErrInternal) = true, want false")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := &os.PathError{Op: "open", Path: "/db", Err: os.ErrNotExist}
+	e := Wrap(Internal, cause)
+	if e.Code != Internal || e.Message != Internal.String() {
+		t.Errorf("Wrap = %v, want an Internal Err with the default message", e)
+	}
+	if errors.Unwrap(e) != cause {
+		t.Errorf("errors.Unwrap = %v, want %v", errors.Unwrap(e), cause)
+	}
+	var target *os.PathError
+	if !errors.As(e, &target) || target != cause {
+		t.Errorf("errors.As did not find the *os.PathError cause")
+	}
+	if !strings.Contains(e.Error(), cause.Error()) {
+		t.Errorf("Error() = %q, want it to contain the cause %q", e.Error(), cause.Error())
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "/db") {
+		t.Errorf("json.Marshal = %s, want the cause not to be serialized", data)
+	}
+}
+
+func TestValid(t *testing.T) {
+	last := AllCodes()[len(AllCodes())-1]
+	tests := []struct {
+		code Code
+		want bool
+	}{
+		{Success, true},
+		{last, true},
+		{last + 1, false},
+		{Code(1 << 31), false},
+	}
+	for _, tt := range tests {
+		if got := tt.code.Valid(); got != tt.want {
+			t.Errorf("Code(%d).Valid() = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNewErrInvalidCode(t *testing.T) {
+	e := NewErr(Code(1<<31), "")
+	if e.Code != Internal {
+		t.Errorf("NewErr(invalid).Code = %v, want internal", e.Code.Name())
+	}
+	if e.Message != Internal.String() {
+		t.Errorf("NewErr(invalid).Message = %q, want %q", e.Message, Internal.String())
+	}
+}
+
+func TestNewErrf(t *testing.T) {
+	e := NewErrf(BadInputData, "field %q too long", "name")
+	if e.Code != BadInputData || e.Message != `field "name" too long` {
+		t.Errorf("NewErrf = %v, want a formatted bad_input_data Err", e)
+	}
+	e = NewErrf(NotFound, "")
+	if e.Message != NotFound.String() {
+		t.Errorf("NewErrf with an empty format = %q, want %q", e.Message, NotFound.String())
+	}
+}
+
+func TestCodeFromError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{nil, Success},
+		{NewErr(NotFound, ""), NotFound},
+		{fmt.Errorf("get: %w", NewErr(PermissionDenied, "")), PermissionDenied},
+		{NewErrorResponse(nil, NewErr(TooBig, "")), TooBig},
+		{errors.New("boom"), Internal},
+		{NewErrorResponse(nil, nil), Internal},
+	}
+	for _, tt := range tests {
+		if got := CodeFromError(tt.err); got != tt.want {
+			t.Errorf("CodeFromError(%v) = %v, want %v", tt.err, got.Name(), tt.want.Name())
+		}
+	}
+}
+
+func TestErrorResponseError(t *testing.T) {
+	res := newResponse(404, "")
+	msg := NewErrorResponse(res, NewErr(NotFound, "no such file")).Error()
+	if want := "GET http://example.com/files?token=REDACTED: 404 (6: no such file)"; msg != want {
+		t.Errorf("Error() = %q, want %q", msg, want)
+	}
+	if strings.Contains(msg, "0x") {
+		t.Errorf("Error() = %q, want no func reference", msg)
+	}
+}
+
+func TestStringDefault(t *testing.T) {
+	for _, c := range AllCodes() {
+		if msg := c.String(); msg == "" || strings.Contains(msg, "FIXME") || msg == "unknown error" {
+			t.Errorf("%v.String() = %q, want a specific message", c.Name(), msg)
+		}
+	}
+	if got := Success.String(); got != "success" {
+		t.Errorf("Success.String() = %q, want success", got)
+	}
+	if got := Code(999).String(); got != "unknown error" {
+		t.Errorf("Code(999).String() = %q, want unknown error", got)
+	}
+}
+
+func TestName(t *testing.T) {
+	want := []string{
+		"success", "invalid_token", "unauthenticated", "bad_authentication_data",
+		"bad_input_data", "internal", "not_found", "bad_checksum", "too_big",
+		"permission_denied", "already_exists", "rate_limited", "timeout",
+		"canceled", "unavailable",
+	}
+	all := AllCodes()
+	if len(all) != len(want) {
+		t.Fatalf("%d codes, want %d", len(all), len(want))
+	}
+	snake := regexp.MustCompile(`^[a-z]+(_[a-z]+)*$`)
+	seen := map[string]bool{}
+	for i, c := range all {
+		name := c.Name()
+		if name != want[i] {
+			t.Errorf("Code(%d).Name() = %q, want %q", c, name, want[i])
+		}
+		if !snake.MatchString(name) {
+			t.Errorf("Code(%d).Name() = %q, want snake case", c, name)
+		}
+		if seen[name] {
+			t.Errorf("duplicated name %q", name)
+		}
+		seen[name] = true
+	}
+	if got := Code(999).Name(); got != "unknown" {
+		t.Errorf("Code(999).Name() = %q, want unknown", got)
+	}
+}
+
+func TestAllCodes(t *testing.T) {
+	all := AllCodes()
+	if len(all) != int(Unavailable)+1 {
+		t.Fatalf("len(AllCodes()) = %d, want %d", len(all), int(Unavailable)+1)
+	}
+	for i, c := range all {
+		if c != Code(i) {
+			t.Errorf("AllCodes()[%d] = %d, want iota order", i, c)
+		}
+	}
+	all[0] = Internal
+	if AllCodes()[0] != Success {
+		t.Error("modifying the result of AllCodes changed the package state")
+	}
+}
+
+func TestErrorResponseErrorNilSafe(t *testing.T) {
+	e := NewErr(Unavailable, "dial failed")
+	if got, want := NewErrorResponse(nil, e).Error(), "14: dial failed"; got != want {
+		t.Errorf("Error() without response = %q, want %q", got, want)
+	}
+	res := &http.Response{StatusCode: 503, Header: http.Header{}}
+	if got, want := NewErrorResponse(res, e).Error(), "503 (14: dial failed)"; got != want {
+		t.Errorf("Error() without request = %q, want %q", got, want)
+	}
+	if got := NewErrorResponse(nil, nil).Error(); got != Internal.String() {
+		t.Errorf("Error() without Err = %q, want %q", got, Internal.String())
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	if got, want := Unavailable.String(), "service temporarily unavailable"; got != want {
+		t.Errorf("Unavailable.String() = %q, want %q", got, want)
+	}
+	if got := Unavailable.HTTPStatus(); got != http.StatusServiceUnavailable {
+		t.Errorf("Unavailable.HTTPStatus() = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if !Unavailable.Retryable() {
+		t.Error("Unavailable.Retryable() = false, want true")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	if got, want := Timeout.String(), "request timed out"; got != want {
+		t.Errorf("Timeout.String() = %q, want %q", got, want)
+	}
+	if got := Timeout.HTTPStatus(); got != http.StatusGatewayTimeout {
+		t.Errorf("Timeout.HTTPStatus() = %d, want %d", got, http.StatusGatewayTimeout)
+	}
+	if got := CodeFromHTTPStatus(http.StatusGatewayTimeout); got != Timeout {
+		t.Errorf("CodeFromHTTPStatus(504) = %v, want timeout", got.Name())
+	}
+	if !Timeout.Retryable() {
+		t.Error("Timeout.Retryable() = false, want true")
+	}
+}
+
+func TestSameCode(t *testing.T) {
+	got := fmt.Errorf("handler: %w", NewErr(BadInputData, "missing name"))
+	if !SameCode(got, ErrBadInput) {
+		t.Errorf("SameCode(%v, ErrBadInput) = false, want true", got)
+	}
+	if !SameCode(NewErr(NotFound, "a"), NewErrorResponse(nil, NewErr(NotFound, "b"))) {
+		t.Error("SameCode with differing messages = false, want true")
+	}
+	if SameCode(got, ErrInternal) {
+		t.Errorf("SameCode(%v, ErrInternal) = true, want false", got)
+	}
+	if !SameCode(errors.New("boom"), ErrInternal) {
+		t.Error("SameCode(plain error, ErrInternal) = false, want true")
+	}
+	if !SameCode(nil, nil) {
+		t.Error("SameCode(nil, nil) = false, want true")
+	}
+}
+
+func TestStatusText(t *testing.T) {
+	for _, c := range []Code{Success, BadInputData, NotFound, Internal, RateLimited} {
+		if got, want := c.StatusText(), http.StatusText(c.HTTPStatus()); got != want {
+			t.Errorf("%v.StatusText() = %q, want %q", c.Name(), got, want)
+		}
+	}
+	if got := Canceled.StatusText(); got != "Client Closed Request" {
+		t.Errorf("Canceled.StatusText() = %q, want Client Closed Request", got)
+	}
+}
+
+func TestWithMessage(t *testing.T) {
+	e := ErrInternal.WithMessagef("loading user %d", 42)
+	if e.Code != Internal || e.Message != "loading user 42" {
+		t.Errorf("WithMessagef = %v, want an Internal Err with the formatted message", e)
+	}
+	if ErrInternal.Message != Internal.String() {
+		t.Errorf("WithMessagef modified the sentinel to %v", ErrInternal)
+	}
+	orig := Wrap(NotFound, ErrBadInput)
+	e = orig.WithMessage("other")
+	if e == orig || orig.Message != NotFound.String() || e.Unwrap() != orig.Unwrap() {
+		t.Errorf("WithMessage = %v, want a copy keeping the cause", e)
+	}
+}
+
+func TestResponsePredicates(t *testing.T) {
+	tests := []struct {
+		status                                     int
+		success, clientError, serverError, limited bool
+	}{
+		{200, true, false, false, false},
+		{204, true, false, false, false},
+		{302, false, false, false, false},
+		{400, false, true, false, false},
+		{429, false, true, false, true},
+		{499, false, true, false, false},
+		{500, false, false, true, false},
+		{503, false, false, true, false},
+	}
+	for _, tt := range tests {
+		r := NewResponse(&http.Response{StatusCode: tt.status, Header: http.Header{}})
+		if r.IsSuccess() != tt.success || r.IsClientError() != tt.clientError ||
+			r.IsServerError() != tt.serverError || r.IsRateLimited() != tt.limited {
+			t.Errorf("predicates of %d = %v, %v, %v, %v, want %v, %v, %v, %v", tt.status,
+				r.IsSuccess(), r.IsClientError(), r.IsServerError(), r.IsRateLimited(),
+				tt.success, tt.clientError, tt.serverError, tt.limited)
+		}
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	if got, want := Canceled.String(), "request canceled"; got != want {
+		t.Errorf("Canceled.String() = %q, want %q", got, want)
+	}
+	if got := Canceled.HTTPStatus(); got != 499 {
+		t.Errorf("Canceled.HTTPStatus() = %d, want 499", got)
+	}
+	if got := CodeFromHTTPStatus(499); got != Canceled {
+		t.Errorf("CodeFromHTTPStatus(499) = %v, want canceled", got.Name())
+	}
+	if got := Canceled.Severity(); got != SeverityInfo {
+		t.Errorf("Canceled.Severity() = %v, want info", got)
+	}
+	if Canceled.Retryable() {
+		t.Error("Canceled.Retryable() = true, want false")
+	}
+}
+
+func TestErrEqual(t *testing.T) {
+	base := func() *Err {
+		return NewErrWithReason(BadInputData, "input.invalid", "invalid").WithField("a", "required")
+	}
+	if !base().Equal(base()) {
+		t.Error("equal errors are not Equal")
+	}
+	withCause := Wrap(BadInputData, errors.New("cause")).WithMessage("invalid")
+	if !withCause.Equal(NewErr(BadInputData, "invalid")) {
+		t.Error("errors differing only in their cause are not Equal")
+	}
+	tests := []struct {
+		name string
+		e    *Err
+	}{
+		{"code", NewErrWithReason(NotFound, "input.invalid", "invalid").WithField("a", "required")},
+		{"message", NewErrWithReason(BadInputData, "input.invalid", "other").WithField("a", "required")},
+		{"reason", NewErrWithReason(BadInputData, "input.other", "invalid").WithField("a", "required")},
+		{"field name", NewErrWithReason(BadInputData, "input.invalid", "invalid").WithField("b", "required")},
+		{"field message", NewErrWithReason(BadInputData, "input.invalid", "invalid").WithField("a", "other")},
+		{"field count", NewErrWithReason(BadInputData, "input.invalid", "invalid")},
+		{"nil", nil},
+	}
+	for _, tt := range tests {
+		if base().Equal(tt.e) {
+			t.Errorf("errors differing in their %s are Equal", tt.name)
+		}
+	}
+	var e *Err
+	if !e.Equal(nil) {
+		t.Error("nil errors are not Equal")
+	}
+}
+
+func TestErrorResponseRequestID(t *testing.T) {
+	res := newResponse(500, "")
+	errResp := NewErrorResponse(res, NewErr(Internal, ""))
+	if id := errResp.RequestID(); id != "" {
+		t.Errorf("RequestID() without header = %q, want empty", id)
+	}
+	if strings.Contains(errResp.Error(), "request_id") {
+		t.Errorf("Error() = %q, want no request_id", errResp.Error())
+	}
+
+	res.Header.Set("X-Request-Id", "abc-123")
+	if id := errResp.RequestID(); id != "abc-123" {
+		t.Errorf("RequestID() = %q, want abc-123", id)
+	}
+	if !strings.Contains(errResp.Error(), "request_id=abc-123") {
+		t.Errorf("Error() = %q, want it to contain the request ID", errResp.Error())
+	}
+	data, _ := json.Marshal(errResp)
+	if !strings.Contains(string(data), `"request_id":"abc-123"`) {
+		t.Errorf("json.Marshal = %s, want the request ID", data)
+	}
+
+	var decoded ErrorResponse
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.RequestID() != "abc-123" {
+		t.Errorf("decoded RequestID() = %q, %v, want abc-123", decoded.RequestID(), err)
+	}
+}
+
+func TestWriteErrorRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-Id", "abc-123")
+	WriteError(rec, nil, NewErr(Internal, ""))
+	if !strings.Contains(rec.Body.String(), `"request_id":"abc-123"`) {
+		t.Errorf("body = %s, want the request ID", rec.Body)
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{Success, 2},
+		{BadInputData, 4},
+		{Internal, 5},
+		{Unavailable, 5},
+		{Code(999), 5},
+	}
+	for _, tt := range tests {
+		if got := tt.code.StatusClass(); got != tt.want {
+			t.Errorf("Code(%d).StatusClass() = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestTemporary(t *testing.T) {
+	var temporary interface{ Temporary() bool } = NewErr(Unavailable, "")
+	if !temporary.Temporary() {
+		t.Error("Unavailable is not Temporary")
+	}
+	if NewErr(BadInputData, "").Temporary() {
+		t.Error("BadInputData is Temporary")
+	}
+	for _, c := range AllCodes() {
+		if got := NewErr(c, "").Temporary(); got != c.Retryable() {
+			t.Errorf("%v: Temporary() = %v, want Retryable() = %v", c.Name(), got, c.Retryable())
+		}
+	}
+	if NewErrorResponse(nil, nil).Temporary() {
+		t.Error("an ErrorResponse without Err is Temporary")
+	}
+}
+
+func TestValidationErr(t *testing.T) {
+	e := ValidationErr(map[string]string{
+		"name":  "required",
+		"age":   "must be positive",
+		"email": "invalid",
+	})
+	if e.Code != BadInputData {
+		t.Errorf("Code = %v, want bad_input_data", e.Code.Name())
+	}
+	want := []FieldError{{"age", "must be positive"}, {"email", "invalid"}, {"name", "required"}}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("Fields = %v, want %v", e.Fields, want)
+	}
+	for i := range want {
+		if e.Fields[i] != want[i] {
+			t.Errorf("Fields[%d] = %v, want %v", i, e.Fields[i], want[i])
+		}
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	deprecated[TooBig] = true
+	defer delete(deprecated, TooBig)
+
+	if !TooBig.Deprecated() {
+		t.Error("TooBig.Deprecated() = false after marking it")
+	}
+	if NotFound.Deprecated() {
+		t.Error("NotFound.Deprecated() = true")
+	}
+	if got := DeprecatedCodes(); len(got) != 1 || got[0] != TooBig {
+		t.Errorf("DeprecatedCodes() = %v, want [too_big]", got)
+	}
+}
+
+func TestSetHTTPStatus(t *testing.T) {
+	defaultStatus := BadInputData.HTTPStatus()
+	if err := SetHTTPStatus(BadInputData, http.StatusUnprocessableEntity); err != nil {
+		t.Fatal(err)
+	}
+	defer SetHTTPStatus(BadInputData, 0)
+	if got := BadInputData.HTTPStatus(); got != http.StatusUnprocessableEntity {
+		t.Errorf("BadInputData.HTTPStatus() = %d, want %d", got, http.StatusUnprocessableEntity)
+	}
+	if got := CodeFromHTTPStatus(http.StatusUnprocessableEntity); got != BadInputData {
+		t.Errorf("CodeFromHTTPStatus(422) = %v, want %v", got, BadInputData)
+	}
+	if got := NotFound.HTTPStatus(); got != http.StatusNotFound {
+		t.Errorf("NotFound.HTTPStatus() = %d, want the default %d", got, http.StatusNotFound)
+	}
+
+	for _, status := range []int{-1, 1, 99, 1000} {
+		if err := SetHTTPStatus(NotFound, status); err == nil {
+			t.Errorf("SetHTTPStatus(NotFound, %d) succeeded, want an error", status)
+		}
+	}
+	if got := NotFound.HTTPStatus(); got != http.StatusNotFound {
+		t.Errorf("NotFound.HTTPStatus() after invalid overrides = %d, want %d", got, http.StatusNotFound)
+	}
+
+	if err := SetHTTPStatus(BadInputData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := BadInputData.HTTPStatus(); got != defaultStatus {
+		t.Errorf("BadInputData.HTTPStatus() after reset = %d, want %d", got, defaultStatus)
+	}
+}
+
+func TestSetStrict(t *testing.T) {
+	undefined := Code(987654)
+	SetStrict(true)
+	defer SetStrict(false)
+
+	panics := map[string]func(){
+		"NewErr": func() { NewErr(undefined, "") },
+		"New":    func() { New(undefined) },
+		"String": func() { _ = undefined.String() },
+	}
+	for name, f := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s with an undefined code did not panic in strict mode", name)
+				}
+			}()
+			f()
+		}()
+	}
+	if e := NewErr(NotFound, ""); e.Code != NotFound || e.Message != NotFound.String() {
+		t.Errorf("NewErr(NotFound) in strict mode = %v", e)
+	}
+
+	// codes from remote peers must not panic
+	var e Err
+	if err := json.Unmarshal([]byte(`{"code":987654}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != undefined || e.Message != "unknown error" {
+		t.Errorf("json.Unmarshal of an undefined code = %+v, want the code kept with the fallback message", e)
+	}
+	if e := DecodeError(strings.NewReader(`{"error":{"code":987654}}`), http.StatusServiceUnavailable); e.Code != Unavailable {
+		t.Errorf("DecodeError of an undefined code = %v, want %v", e, Unavailable)
+	}
+	if e, _, err := ParseProblemJSON([]byte(`{"status":404,"code":987654}`)); err != nil || e.Code != NotFound {
+		t.Errorf("ParseProblemJSON of an undefined code = %v, %v, want %v", e, err, NotFound)
+	}
+
+	SetStrict(false)
+	if got := undefined.String(); got != "unknown error" {
+		t.Errorf("String() in lenient mode = %q, want unknown error", got)
+	}
+	if e := NewErr(undefined, ""); e.Code != Internal {
+		t.Errorf("NewErr in lenient mode = %v, want %v", e, Internal)
+	}
+}
+
+func TestSetStrictDecodedCode(t *testing.T) {
+	var e Err
+	if err := json.Unmarshal([]byte(`{"code":987654}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	SetStrict(true)
+	defer SetStrict(false)
+
+	paths := map[string]func(){
+		"MarshalCompact": func() { e.MarshalCompact() },
+		"json.Marshal":   func() { json.Marshal(&e) },
+		"ToProblemJSON":  func() { e.ToProblemJSON("/files/a") },
+		"LocalizedString": func() {
+			if got := e.Code.LocalizedString("es"); got != "unknown error" {
+				t.Errorf("LocalizedString = %q, want unknown error", got)
+			}
+		},
+		"Localize": func() {
+			h := Localize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, &e)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", "es")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		},
+		"Error": func() { _ = e.Error() },
+	}
+	for name, f := range paths {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s with a decoded undefined code panicked in strict mode: %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+
+	data, err := e.MarshalCompact()
+	if err != nil || strings.Contains(string(data), "message") {
+		t.Errorf("MarshalCompact = %s, %v, want the fallback message omitted", data, err)
+	}
+}
@@ -0,0 +1,45 @@
+// Package codestest provides utilities for testing code that uses
+// the codes package.
+package codestest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/clawio/codes"
+)
+
+// NewErrorResponse returns an ErrorResponse for a request with the given method
+// and url that failed with code and msg. The response has the status of the code,
+// an empty body and the request set, so its Error method can be used.
+// It panics if url cannot be parsed.
+func NewErrorResponse(method, url string, code codes.Code, msg string) *codes.ErrorResponse {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		panic(fmt.Sprintf("codestest: invalid request: %v", err))
+	}
+	status := code.HTTPStatus()
+	res := &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}
+	return codes.NewErrorResponse(res, codes.NewErr(code, msg))
+}
+
+// RequireCode fails the test immediately if the Code of err, as returned by
+// codes.CodeFromError, is not want.
+func RequireCode(t testing.TB, err error, want codes.Code) {
+	t.Helper()
+	if got := codes.CodeFromError(err); got != want {
+		t.Fatalf("got code %s (%d), want %s (%d); error: %v", got.Name(), got, want.Name(), want, err)
+	}
+}
@@ -0,0 +1,72 @@
+package codestest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/clawio/codes"
+)
+
+func TestNewErrorResponse(t *testing.T) {
+	errResp := NewErrorResponse("PUT", "https://example.com/files/a?access_token=secret", codes.AlreadyExists, "exists")
+	msg := errResp.Error()
+	for _, want := range []string{"PUT", "https://example.com/files/a?access_token=REDACTED", "409", "exists"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Error() = %q, want it to contain %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "secret") {
+		t.Errorf("Error() = %q, want the URL sanitized", msg)
+	}
+	if errResp.Code != codes.AlreadyExists || errResp.Response.StatusCode != 409 {
+		t.Errorf("ErrorResponse = %v, want already_exists with status 409", errResp)
+	}
+}
+
+func TestNewErrorResponseInvalidURL(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewErrorResponse with an invalid URL did not panic")
+		}
+	}()
+	NewErrorResponse("GET", "://nope", codes.Internal, "")
+}
+
+// fakeTB records the failures reported through it.
+type fakeTB struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Fatalf(format string, args ...interface{}) {
+	t.failed = true
+	t.msg = fmt.Sprintf(format, args...)
+}
+
+func TestRequireCode(t *testing.T) {
+	RequireCode(t, codes.NewErr(codes.NotFound, ""), codes.NotFound)
+	RequireCode(t, fmt.Errorf("stat: %w", codes.NewErr(codes.PermissionDenied, "")), codes.PermissionDenied)
+	RequireCode(t, nil, codes.Success)
+
+	tb := &fakeTB{}
+	RequireCode(tb, codes.NewErr(codes.Internal, ""), codes.NotFound)
+	if !tb.failed {
+		t.Fatal("RequireCode did not fail on a mismatch")
+	}
+	for _, want := range []string{"internal", "not_found"} {
+		if !strings.Contains(tb.msg, want) {
+			t.Errorf("failure message %q does not contain %q", tb.msg, want)
+		}
+	}
+
+	tb = &fakeTB{}
+	RequireCode(tb, errors.New("plain"), codes.NotFound)
+	if !tb.failed {
+		t.Error("RequireCode did not fail on an error without code")
+	}
+}
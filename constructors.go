@@ -0,0 +1,74 @@
+package codes
+
+// The constructors below are shorthands for NewErrf with a fixed Code.
+// They make the intent clear at call sites and easy to grep for.
+
+// InvalidTokenf creates an InvalidToken Err with a formatted message.
+func InvalidTokenf(format string, args ...interface{}) *Err {
+	return NewErrf(InvalidToken, format, args...)
+}
+
+// Unauthenticatedf creates an Unauthenticated Err with a formatted message.
+func Unauthenticatedf(format string, args ...interface{}) *Err {
+	return NewErrf(Unauthenticated, format, args...)
+}
+
+// BadAuthenticationDataf creates a BadAuthenticationData Err with a formatted message.
+func BadAuthenticationDataf(format string, args ...interface{}) *Err {
+	return NewErrf(BadAuthenticationData, format, args...)
+}
+
+// BadInputDataf creates a BadInputData Err with a formatted message.
+func BadInputDataf(format string, args ...interface{}) *Err {
+	return NewErrf(BadInputData, format, args...)
+}
+
+// Internalf creates an Internal Err with a formatted message.
+func Internalf(format string, args ...interface{}) *Err {
+	return NewErrf(Internal, format, args...)
+}
+
+// NotFoundf creates a NotFound Err with a formatted message.
+func NotFoundf(format string, args ...interface{}) *Err {
+	return NewErrf(NotFound, format, args...)
+}
+
+// BadChecksumf creates a BadChecksum Err with a formatted message.
+func BadChecksumf(format string, args ...interface{}) *Err {
+	return NewErrf(BadChecksum, format, args...)
+}
+
+// TooBigf creates a TooBig Err with a formatted message.
+func TooBigf(format string, args ...interface{}) *Err {
+	return NewErrf(TooBig, format, args...)
+}
+
+// PermissionDeniedf creates a PermissionDenied Err with a formatted message.
+func PermissionDeniedf(format string, args ...interface{}) *Err {
+	return NewErrf(PermissionDenied, format, args...)
+}
+
+// AlreadyExistsf creates an AlreadyExists Err with a formatted message.
+func AlreadyExistsf(format string, args ...interface{}) *Err {
+	return NewErrf(AlreadyExists, format, args...)
+}
+
+// RateLimitedf creates a RateLimited Err with a formatted message.
+func RateLimitedf(format string, args ...interface{}) *Err {
+	return NewErrf(RateLimited, format, args...)
+}
+
+// Timeoutf creates a Timeout Err with a formatted message.
+func Timeoutf(format string, args ...interface{}) *Err {
+	return NewErrf(Timeout, format, args...)
+}
+
+// Canceledf creates a Canceled Err with a formatted message.
+func Canceledf(format string, args ...interface{}) *Err {
+	return NewErrf(Canceled, format, args...)
+}
+
+// Unavailablef creates an Unavailable Err with a formatted message.
+func Unavailablef(format string, args ...interface{}) *Err {
+	return NewErrf(Unavailable, format, args...)
+}
@@ -0,0 +1,39 @@
+package codes
+
+import (
+	"testing"
+)
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		new  func(string, ...interface{}) *Err
+		want Code
+	}{
+		{InvalidTokenf, InvalidToken},
+		{Unauthenticatedf, Unauthenticated},
+		{BadAuthenticationDataf, BadAuthenticationData},
+		{BadInputDataf, BadInputData},
+		{Internalf, Internal},
+		{NotFoundf, NotFound},
+		{BadChecksumf, BadChecksum},
+		{TooBigf, TooBig},
+		{PermissionDeniedf, PermissionDenied},
+		{AlreadyExistsf, AlreadyExists},
+		{RateLimitedf, RateLimited},
+		{Timeoutf, Timeout},
+		{Canceledf, Canceled},
+		{Unavailablef, Unavailable},
+	}
+	if len(tests) != len(AllCodes())-1 {
+		t.Fatalf("%d constructors tested, want one per code but Success", len(tests))
+	}
+	for _, tt := range tests {
+		e := tt.new("item %d", 7)
+		if e.Code != tt.want || e.Message != "item 7" {
+			t.Errorf("constructor for %v = %v", tt.want.Name(), e)
+		}
+		if e := tt.new(""); e.Message != tt.want.String() {
+			t.Errorf("constructor for %v with an empty format = %q, want %q", tt.want.Name(), e.Message, tt.want.String())
+		}
+	}
+}
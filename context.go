@@ -0,0 +1,21 @@
+package codes
+
+import (
+	"context"
+	"errors"
+)
+
+// FromContextErr converts the errors of a context into an Err:
+// context.DeadlineExceeded becomes Timeout and context.Canceled becomes Canceled.
+// The original error is kept as the cause. The boolean is false, and the Err nil,
+// for unrelated errors.
+func FromContextErr(err error) (*Err, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(Timeout, err), true
+	case errors.Is(err, context.Canceled):
+		return Wrap(Canceled, err), true
+	default:
+		return nil, false
+	}
+}
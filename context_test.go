@@ -0,0 +1,36 @@
+package codes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFromContextErr(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	e, ok := FromContextErr(fmt.Errorf("calling storage: %w", ctx.Err()))
+	if !ok || e.Code != Timeout {
+		t.Errorf("FromContextErr(deadline) = %v, %v, want timeout, true", e, ok)
+	}
+	if !errors.Is(e, context.DeadlineExceeded) {
+		t.Error("the Err does not keep context.DeadlineExceeded as its cause")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	e, ok = FromContextErr(ctx.Err())
+	if !ok || e.Code != Canceled {
+		t.Errorf("FromContextErr(canceled) = %v, %v, want canceled, true", e, ok)
+	}
+
+	if e, ok := FromContextErr(errors.New("boom")); ok || e != nil {
+		t.Errorf("FromContextErr(unrelated) = %v, %v, want nil, false", e, ok)
+	}
+	if e, ok := FromContextErr(nil); ok || e != nil {
+		t.Errorf("FromContextErr(nil) = %v, %v, want nil, false", e, ok)
+	}
+}
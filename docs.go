@@ -0,0 +1,34 @@
+package codes
+
+import (
+	"strings"
+	"sync"
+)
+
+// docBaseURL holds the base URL of the error reference documentation.
+var docBaseURL = struct {
+	sync.RWMutex
+	base string
+}{}
+
+// SetDocBaseURL sets the base URL of the error reference documentation.
+// When set, serialized Errs include a doc_url field with the value
+// base + "/" + code.Name(). An empty base disables the field.
+// The setting is global and is meant to be applied at startup.
+func SetDocBaseURL(base string) {
+	docBaseURL.Lock()
+	docBaseURL.base = strings.TrimSuffix(base, "/")
+	docBaseURL.Unlock()
+}
+
+// DocURL returns the documentation URL of the Code, or an empty string
+// if no base URL has been set with SetDocBaseURL.
+func (c Code) DocURL() string {
+	docBaseURL.RLock()
+	base := docBaseURL.base
+	docBaseURL.RUnlock()
+	if base == "" {
+		return ""
+	}
+	return base + "/" + c.Name()
+}
@@ -0,0 +1,27 @@
+package codes
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDocURL(t *testing.T) {
+	if got := NotFound.DocURL(); got != "" {
+		t.Errorf("DocURL() without base = %q, want empty", got)
+	}
+	data, _ := json.Marshal(NewErr(NotFound, ""))
+	if strings.Contains(string(data), "doc_url") {
+		t.Errorf("json.Marshal without base = %s, want no doc_url", data)
+	}
+
+	SetDocBaseURL("https://docs.example.com/errors/")
+	defer SetDocBaseURL("")
+	if got, want := NotFound.DocURL(), "https://docs.example.com/errors/not_found"; got != want {
+		t.Errorf("DocURL() = %q, want %q", got, want)
+	}
+	data, _ = json.Marshal(NewErr(NotFound, ""))
+	if !strings.Contains(string(data), `"doc_url":"https://docs.example.com/errors/not_found"`) {
+		t.Errorf("json.Marshal with base = %s, want the doc_url", data)
+	}
+}
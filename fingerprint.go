@@ -0,0 +1,40 @@
+package codes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	uuidRegexp   = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	hexRegexp    = regexp.MustCompile(`\b[0-9a-f]{8,}\b`)
+	numberRegexp = regexp.MustCompile(`[0-9]+`)
+)
+
+// normalizeMessage strips the variable parts of an error message.
+func normalizeMessage(msg string) string {
+	msg = strings.ToLower(msg)
+	msg = uuidRegexp.ReplaceAllString(msg, "<uuid>")
+	msg = hexRegexp.ReplaceAllString(msg, "<hex>")
+	msg = numberRegexp.ReplaceAllString(msg, "<n>")
+	return strings.Join(strings.Fields(msg), " ")
+}
+
+// Fingerprint returns a short stable hash that groups errors that are logically
+// the same, e.g. for deduplication in an error-tracking system. It is computed
+// from the Code, the Reason and the message normalized as follows: the message
+// is lower-cased, UUIDs, hexadecimal strings of 8 or more characters and runs of
+// digits are replaced by placeholders, and whitespace is collapsed.
+// Errors that differ only in an interpolated ID get the same fingerprint.
+func (e *Err) Fingerprint() string {
+	code, _ := e.Code.MarshalText()
+	h := sha256.New()
+	h.Write(code)
+	h.Write([]byte{0})
+	h.Write([]byte(e.Reason))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeMessage(e.Message)))
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
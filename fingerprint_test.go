@@ -0,0 +1,37 @@
+package codes
+
+import (
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	same := []*Err{
+		NewErrf(NotFound, "user %d not found", 42),
+		NewErrf(NotFound, "user %d not found", 1337),
+		NewErrf(NotFound, "User %d  not found", 7),
+	}
+	want := same[0].Fingerprint()
+	if len(want) != 16 {
+		t.Errorf("Fingerprint() = %q, want 16 hex characters", want)
+	}
+	for _, e := range same[1:] {
+		if got := e.Fingerprint(); got != want {
+			t.Errorf("Fingerprint(%q) = %s, want %s", e.Message, got, want)
+		}
+	}
+	if a, b := NewErrf(NotFound, "file %s", "3f2c1a9b-1c2d-4e5f-8a9b-0c1d2e3f4a5b"),
+		NewErrf(NotFound, "file %s", "00000000-1111-2222-3333-444444444444"); a.Fingerprint() != b.Fingerprint() {
+		t.Error("errors differing only in a UUID have different fingerprints")
+	}
+
+	different := []*Err{
+		NewErrf(Internal, "user %d not found", 42),
+		NewErrWithReason(NotFound, "user.deleted", "user 42 not found"),
+		NewErrf(NotFound, "group %d not found", 42),
+	}
+	for _, e := range different {
+		if e.Fingerprint() == want {
+			t.Errorf("Fingerprint(%v) = %s, want it different", e, want)
+		}
+	}
+}
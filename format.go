@@ -0,0 +1,68 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Format implements the fmt.Formatter interface:
+//
+//	%s    the message
+//	%q    the quoted message
+//	%v    the same as Error(), "code: message" followed by the cause, if any
+//	%+v   the same as %v followed by the cause chain and the stack, if any
+//
+// A nil Err is formatted as "<nil>".
+func (e *Err) Format(s fmt.State, verb rune) {
+	if e == nil {
+		io.WriteString(s, "<nil>")
+		return
+	}
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			e.formatDetails(s)
+		}
+	case 's':
+		io.WriteString(s, e.Message)
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Message)
+	default:
+		fmt.Fprintf(s, "%%!%c(*codes.Err=%s)", verb, e.Error())
+	}
+}
+
+// formatDetails writes the cause chain and the stack of the Err to w.
+func (e *Err) formatDetails(w io.Writer) {
+	for cause := e.cause; cause != nil; cause = errors.Unwrap(cause) {
+		fmt.Fprintf(w, "\ncaused by: %v", cause)
+	}
+	if stack := e.FormatStack(); stack != "" {
+		io.WriteString(w, "\n"+stack)
+	}
+}
+
+// Format implements the fmt.Formatter interface. It takes precedence over
+// the Format method of the embedded Err, so the request and status of the
+// response are kept:
+//
+//	%s, %v  the same as Error()
+//	%q      the quoted Error()
+//	%+v     the same as %v followed by the cause chain and the stack of the Err, if any
+func (r *ErrorResponse) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, r.Error())
+		if s.Flag('+') && r.Err != nil {
+			r.Err.formatDetails(s)
+		}
+	case 's':
+		io.WriteString(s, r.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", r.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(*codes.ErrorResponse=%s)", verb, r.Error())
+	}
+}
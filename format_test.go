@@ -0,0 +1,77 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrFormat(t *testing.T) {
+	cause := fmt.Errorf("open /data: %w", errors.New("disk full"))
+	e := Wrap(Internal, cause).WithMessage("cannot save")
+	want := e.Error()
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "cannot save"},
+		{"%q", `"cannot save"`},
+		{"%v", want},
+		{"%+v", want + "\ncaused by: open /data: disk full\ncaused by: disk full"},
+		{"%d", "%!d(*codes.Err=" + want + ")"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, e); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if !strings.Contains(want, "disk full") {
+		t.Errorf("%%v = %q, want it to include the cause", want)
+	}
+
+	var nilErr *Err
+	if got := fmt.Sprintf("%v", nilErr); got != "<nil>" {
+		t.Errorf("Sprintf(%%v, nil) = %q, want <nil>", got)
+	}
+}
+
+func TestErrFormatStack(t *testing.T) {
+	e := NewErrWithStack(Internal, "boom")
+	got := fmt.Sprintf("%+v", e)
+	if !strings.HasPrefix(got, e.Error()+"\n") || !strings.Contains(got, "format_test.go") {
+		t.Errorf("Sprintf(%%+v) = %q, want the message followed by the stack", got)
+	}
+	if got := fmt.Sprintf("%v", e); got != e.Error() {
+		t.Errorf("Sprintf(%%v) = %q, want %q", got, e.Error())
+	}
+}
+
+func TestErrorResponseFormat(t *testing.T) {
+	res := newResponse(http.StatusNotFound, "")
+	r := &ErrorResponse{Response: res, Err: Wrap(NotFound, errors.New("no row"))}
+	want := r.Error()
+	if !strings.Contains(want, "404") || !strings.Contains(want, "no row") {
+		t.Fatalf("Error() = %q, want the status and the cause", want)
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", want},
+		{"%v", want},
+		{"%q", fmt.Sprintf("%q", want)},
+		{"%+v", want + "\ncaused by: no row"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, r); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	empty := &ErrorResponse{Response: res}
+	if got := fmt.Sprintf("%+v", empty); got != empty.Error() {
+		t.Errorf("Sprintf(%%+v) without Err = %q, want %q", got, empty.Error())
+	}
+}
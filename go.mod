@@ -0,0 +1,5 @@
+module github.com/clawio/codes
+
+go 1.21
+
+require google.golang.org/grpc v1.64.0
@@ -0,0 +1,121 @@
+// Package grpc translates between ClawIO codes and gRPC status codes.
+// It lives in its own package so the core codes package stays free of
+// the gRPC dependency.
+package grpc
+
+import (
+	"github.com/clawio/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCCode returns the gRPC code that corresponds to c.
+// Unknown codes are reported as grpccodes.Internal.
+func GRPCCode(c codes.Code) grpccodes.Code {
+	switch c {
+	case codes.Success:
+		return grpccodes.OK
+	case codes.InvalidToken, codes.Unauthenticated, codes.BadAuthenticationData:
+		return grpccodes.Unauthenticated
+	case codes.PermissionDenied:
+		return grpccodes.PermissionDenied
+	case codes.BadInputData:
+		return grpccodes.InvalidArgument
+	case codes.NotFound:
+		return grpccodes.NotFound
+	case codes.AlreadyExists:
+		return grpccodes.AlreadyExists
+	case codes.BadChecksum:
+		return grpccodes.DataLoss
+	case codes.TooBig, codes.RateLimited:
+		return grpccodes.ResourceExhausted
+	case codes.Timeout:
+		return grpccodes.DeadlineExceeded
+	case codes.Canceled:
+		return grpccodes.Canceled
+	case codes.Unavailable:
+		return grpccodes.Unavailable
+	default:
+		return grpccodes.Internal
+	}
+}
+
+// FromGRPCCode returns the code that corresponds to the gRPC code c.
+// Like CodeFromHTTPStatus, the mapping is lossy and unmatched codes map to Internal.
+func FromGRPCCode(c grpccodes.Code) codes.Code {
+	switch c {
+	case grpccodes.OK:
+		return codes.Success
+	case grpccodes.Unauthenticated:
+		return codes.Unauthenticated
+	case grpccodes.PermissionDenied:
+		return codes.PermissionDenied
+	case grpccodes.InvalidArgument:
+		return codes.BadInputData
+	case grpccodes.NotFound:
+		return codes.NotFound
+	case grpccodes.AlreadyExists:
+		return codes.AlreadyExists
+	case grpccodes.DataLoss:
+		return codes.BadChecksum
+	case grpccodes.ResourceExhausted:
+		return codes.RateLimited
+	case grpccodes.DeadlineExceeded:
+		return codes.Timeout
+	case grpccodes.Canceled:
+		return codes.Canceled
+	case grpccodes.Unavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// Status returns the gRPC status that corresponds to e, with the code
+// given by GRPCCode and the message of e. A nil e yields an OK status.
+func Status(e *codes.Err) *status.Status {
+	if e == nil {
+		return status.New(grpccodes.OK, "")
+	}
+	return status.New(GRPCCode(e.Code), e.Message)
+}
+
+// Error returns e as an error that implements the GRPCStatus method recognized
+// by the gRPC status package, so it can be returned directly from gRPC handlers.
+// The returned error unwraps to e. A nil e yields a nil error.
+func Error(e *codes.Err) error {
+	if e == nil {
+		return nil
+	}
+	return &statusError{e}
+}
+
+// statusError is an *codes.Err that can be converted to a gRPC status.
+type statusError struct {
+	*codes.Err
+}
+
+// GRPCStatus returns the gRPC status of the error.
+func (e *statusError) GRPCStatus() *status.Status {
+	return Status(e.Err)
+}
+
+// Unwrap returns the underlying *codes.Err.
+func (e *statusError) Unwrap() error {
+	return e.Err
+}
+
+// FromGRPCError converts an error returned by a gRPC call into an Err,
+// mapping its status code with FromGRPCCode and keeping its message.
+// Errors without a gRPC status are reported as Internal, and a nil
+// error yields a nil Err.
+func FromGRPCError(err error) *codes.Err {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return codes.Wrap(codes.Internal, err)
+	}
+	return codes.NewErr(FromGRPCCode(s.Code()), s.Message())
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clawio/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCCode(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want grpccodes.Code
+	}{
+		{codes.Success, grpccodes.OK},
+		{codes.InvalidToken, grpccodes.Unauthenticated},
+		{codes.Unauthenticated, grpccodes.Unauthenticated},
+		{codes.BadAuthenticationData, grpccodes.Unauthenticated},
+		{codes.BadInputData, grpccodes.InvalidArgument},
+		{codes.Internal, grpccodes.Internal},
+		{codes.NotFound, grpccodes.NotFound},
+		{codes.BadChecksum, grpccodes.DataLoss},
+		{codes.TooBig, grpccodes.ResourceExhausted},
+		{codes.PermissionDenied, grpccodes.PermissionDenied},
+		{codes.AlreadyExists, grpccodes.AlreadyExists},
+		{codes.RateLimited, grpccodes.ResourceExhausted},
+		{codes.Timeout, grpccodes.DeadlineExceeded},
+		{codes.Canceled, grpccodes.Canceled},
+		{codes.Unavailable, grpccodes.Unavailable},
+		{codes.Code(999), grpccodes.Internal},
+	}
+	for _, tt := range tests {
+		if got := GRPCCode(tt.code); got != tt.want {
+			t.Errorf("GRPCCode(%v) = %v, want %v", tt.code.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestFromGRPCCodeRoundTrip(t *testing.T) {
+	for _, c := range []grpccodes.Code{
+		grpccodes.OK, grpccodes.Canceled, grpccodes.InvalidArgument, grpccodes.DeadlineExceeded,
+		grpccodes.NotFound, grpccodes.AlreadyExists, grpccodes.PermissionDenied,
+		grpccodes.ResourceExhausted, grpccodes.Internal, grpccodes.Unavailable,
+		grpccodes.DataLoss, grpccodes.Unauthenticated,
+	} {
+		if got := GRPCCode(FromGRPCCode(c)); got != c {
+			t.Errorf("GRPCCode(FromGRPCCode(%v)) = %v", c, got)
+		}
+	}
+	for _, c := range []grpccodes.Code{grpccodes.Unknown, grpccodes.Unimplemented, grpccodes.Aborted} {
+		if got := FromGRPCCode(c); got != codes.Internal {
+			t.Errorf("FromGRPCCode(%v) = %v, want internal", c, got.Name())
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	e := codes.NewErr(codes.NotFound, "no such file")
+	err := Error(e)
+	s, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("status.FromError(%v) is not a status", err)
+	}
+	if s.Code() != grpccodes.NotFound || s.Message() != "no such file" {
+		t.Errorf("status = %v: %q, want NotFound: no such file", s.Code(), s.Message())
+	}
+	var target *codes.Err
+	if !errors.As(err, &target) || target != e {
+		t.Error("the error does not unwrap to the Err")
+	}
+	if Error(nil) != nil {
+		t.Error("Error(nil) != nil")
+	}
+	if s := Status(nil); s.Code() != grpccodes.OK {
+		t.Errorf("Status(nil) = %v, want OK", s.Code())
+	}
+}
+
+func TestFromGRPCError(t *testing.T) {
+	e := FromGRPCError(status.Error(grpccodes.InvalidArgument, "bad name"))
+	if e.Code != codes.BadInputData || e.Message != "bad name" {
+		t.Errorf("FromGRPCError(InvalidArgument) = %v, want bad_input_data: bad name", e)
+	}
+
+	plain := errors.New("connection reset")
+	e = FromGRPCError(plain)
+	if e.Code != codes.Internal || !errors.Is(e, plain) {
+		t.Errorf("FromGRPCError(plain) = %v, want Internal wrapping the error", e)
+	}
+
+	if e := FromGRPCError(nil); e != nil {
+		t.Errorf("FromGRPCError(nil) = %v, want nil", e)
+	}
+
+	orig := codes.NewErr(codes.PermissionDenied, "nope")
+	if e := FromGRPCError(Error(orig)); e.Code != orig.Code || e.Message != orig.Message {
+		t.Errorf("FromGRPCError(Error(%v)) = %v", orig, e)
+	}
+}
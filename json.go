@@ -0,0 +1,195 @@
+package codes
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// numericCodes is 1 when codes are marshaled to JSON as numbers.
+// It is accessed atomically.
+var numericCodes int32
+
+// UseNumericCodes sets whether Code.MarshalJSON encodes codes as their legacy
+// numeric value (5) instead of their Name ("internal"). Numbers are what services
+// predating the string form expect, but they are unreadable in logs and tie clients
+// to the iota ordering, so new deployments should keep the default string form.
+// UnmarshalJSON accepts both forms regardless of this setting, and map keys are
+// always encoded as names. The setting is global and is meant to be applied at startup.
+func UseNumericCodes(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&numericCodes, v)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Defined codes are encoded as their Name (e.g. "bad_input_data"),
+// unknown codes fall back to their numeric value. See UseNumericCodes.
+func (c Code) MarshalJSON() ([]byte, error) {
+	if !c.Valid() || atomic.LoadInt32(&numericCodes) == 1 {
+		return []byte(strconv.FormatUint(uint64(c), 10)), nil
+	}
+	return json.Marshal(c.Name())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts both the string identifier ("internal") and the legacy
+// numeric form (5), so old and new services can interoperate.
+func (c *Code) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return c.UnmarshalText([]byte(s))
+	}
+	n, err := strconv.ParseUint(string(data), 10, 32)
+	if err != nil {
+		return fmt.Errorf("codes: invalid code %s", data)
+	}
+	*c = Code(n)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// Defined codes are encoded as their Name,
+// unknown codes fall back to their decimal value.
+func (c Code) MarshalText() ([]byte, error) {
+	if !c.Valid() {
+		return []byte(strconv.FormatUint(uint64(c), 10)), nil
+	}
+	return []byte(c.Name()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts the Name of the code as well as its decimal value.
+func (c *Code) UnmarshalText(text []byte) error {
+	if n, err := strconv.ParseUint(string(text), 10, 32); err == nil {
+		*c = Code(n)
+		return nil
+	}
+	code, err := ParseCode(string(text))
+	if err != nil {
+		return err
+	}
+	*c = code
+	return nil
+}
+
+// errJSON is the wire representation of an Err.
+type errJSON struct {
+	Message    string          `json:"message"`
+	Code       Code            `json:"code"`
+	RetryAfter int64           `json:"retry_after,omitempty"`
+	Fields     []FieldError    `json:"fields,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+	DocURL     string          `json:"doc_url,omitempty"`
+	Details    json.RawMessage `json:"details,omitempty"`
+}
+
+// wire returns the wire representation of the Err.
+func (e Err) wire() errJSON {
+	// round up so clients never retry too early
+	retryAfter := int64(e.RetryAfter / time.Second)
+	if e.RetryAfter%time.Second > 0 {
+		retryAfter++
+	}
+	return errJSON{
+		Message:    e.Message,
+		Code:       e.Code,
+		RetryAfter: retryAfter,
+		Fields:     e.Fields,
+		Reason:     e.Reason,
+		DocURL:     e.Code.DocURL(),
+		Details:    e.Details,
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (e Err) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.wire())
+}
+
+// compactErrJSON is the wire representation of an Err without its message.
+type compactErrJSON struct {
+	errJSON
+	Message string `json:"message,omitempty"`
+}
+
+// MarshalCompact works like MarshalJSON but omits the message when it is the
+// default message of the code, since clients can reconstruct it from the code.
+// Custom messages are always kept.
+func (e Err) MarshalCompact() ([]byte, error) {
+	v := compactErrJSON{errJSON: e.wire()}
+	if msg, _ := e.Code.message(); e.Message != msg {
+		v.Message = e.Message
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// A missing message, as produced by MarshalCompact, is replaced by
+// the default message of the code. Undefined codes never panic here,
+// even in strict mode, since they may come from a remote peer.
+func (e *Err) UnmarshalJSON(data []byte) error {
+	var v errJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Message == "" {
+		v.Message, _ = v.Code.message()
+	}
+	e.Message = v.Message
+	e.Code = v.Code
+	e.RetryAfter = seconds(v.RetryAfter)
+	e.Fields = v.Fields
+	e.Reason = v.Reason
+	e.Details = v.Details
+	return nil
+}
+
+// errorResponseJSON is the wire representation of an ErrorResponse.
+type errorResponseJSON struct {
+	Err       *Err   `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// It is needed so the methods promoted from the embedded Err
+// do not drop the "error" envelope.
+func (r ErrorResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errorResponseJSON{Err: r.Err, RequestID: r.RequestID()})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *ErrorResponse) UnmarshalJSON(data []byte) error {
+	var v errorResponseJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	r.Err = v.Err
+	r.requestID = v.RequestID
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The Code is encoded as a big-endian uint32 in 4 bytes.
+func (c Code) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, uint32(c))
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (c *Code) UnmarshalBinary(data []byte) error {
+	if len(data) != 4 {
+		return fmt.Errorf("codes: invalid binary code length %d", len(data))
+	}
+	*c = Code(binary.BigEndian.Uint32(data))
+	return nil
+}
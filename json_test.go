@@ -0,0 +1,386 @@
+package codes
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodeMarshalJSON(t *testing.T) {
+	tests := []struct {
+		code Code
+		want string
+	}{
+		{Success, `"success"`},
+		{BadInputData, `"bad_input_data"`},
+		{Internal, `"internal"`},
+		{Code(999), `999`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("json.Marshal(Code(%d)) = %s, want %s", tt.code, data, tt.want)
+		}
+	}
+}
+
+func TestErrJSONRoundTrip(t *testing.T) {
+	for _, c := range AllCodes() {
+		e := NewErr(c, "some message")
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Err
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", data, err)
+		}
+		if !got.Equal(e) {
+			t.Errorf("round trip of %s = %+v, want %+v", data, got, *e)
+		}
+	}
+}
+
+func TestCodeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Code
+	}{
+		{`"bad_input_data"`, BadInputData},
+		{`"INTERNAL"`, Internal},
+		{`4`, BadInputData},
+		{`0`, Success},
+	}
+	for _, tt := range tests {
+		var c Code
+		if err := json.Unmarshal([]byte(tt.in), &c); err != nil {
+			t.Errorf("json.Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+		if c != tt.want {
+			t.Errorf("json.Unmarshal(%s) = %v, want %v", tt.in, c.Name(), tt.want.Name())
+		}
+	}
+}
+
+func TestCodeUnmarshalJSONInvalid(t *testing.T) {
+	for _, in := range []string{`true`, `"nope"`, `-1`, `1.5`, `{}`, `4294967296`} {
+		c := Internal
+		if err := json.Unmarshal([]byte(in), &c); err == nil {
+			t.Errorf("json.Unmarshal(%s) = %v, want an error", in, c.Name())
+		}
+	}
+}
+
+func TestErrRetryAfterJSON(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{0, ""},
+		{30 * time.Second, `"retry_after":30`},
+		{1500 * time.Millisecond, `"retry_after":2`},
+		{math.MaxInt64, `"retry_after":9223372037`},
+	}
+	for _, tt := range tests {
+		e := NewErr(RateLimited, "")
+		e.RetryAfter = tt.retryAfter
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.want == "" {
+			if strings.Contains(string(data), "retry_after") {
+				t.Errorf("json.Marshal with RetryAfter %v = %s, want no retry_after", tt.retryAfter, data)
+			}
+			continue
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("json.Marshal with RetryAfter %v = %s, want %s", tt.retryAfter, data, tt.want)
+		}
+	}
+
+	var e Err
+	if err := json.Unmarshal([]byte(`{"code":"rate_limited","message":"slow down","retry_after":7}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", e.RetryAfter)
+	}
+	if err := json.Unmarshal([]byte(`{"code":"rate_limited","retry_after":9223372037}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.RetryAfter != math.MaxInt64 {
+		t.Errorf("RetryAfter of 9223372037 seconds = %v, want the largest Duration", e.RetryAfter)
+	}
+}
+
+func TestValidationErrJSON(t *testing.T) {
+	e := NewValidationErr(
+		FieldError{Field: "email", Message: "required"},
+		FieldError{Field: "age", Message: "must be positive"},
+	)
+	if e.Code != BadInputData {
+		t.Errorf("Code = %v, want bad_input_data", e.Code.Name())
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"message":"one or more fields are not valid","code":"bad_input_data",` +
+		`"fields":[{"field":"email","message":"required"},{"field":"age","message":"must be positive"}]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+
+	data, err = json.Marshal(NewErr(BadInputData, "nope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"message":"nope","code":"bad_input_data"}`; string(data) != want {
+		t.Errorf("json.Marshal without fields = %s, want %s", data, want)
+	}
+}
+
+func TestCodeText(t *testing.T) {
+	for _, c := range AllCodes() {
+		text, err := c.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(text) != c.Name() {
+			t.Errorf("%v.MarshalText() = %s, want %s", c.Name(), text, c.Name())
+		}
+		var got Code
+		if err := got.UnmarshalText(text); err != nil || got != c {
+			t.Errorf("UnmarshalText(%s) = %v, %v, want %v", text, got.Name(), err, c.Name())
+		}
+	}
+	var got Code
+	if err := got.UnmarshalText([]byte("nope")); err == nil {
+		t.Error("UnmarshalText(nope) succeeded, want an error")
+	}
+}
+
+func TestCodeMapKeys(t *testing.T) {
+	m := map[Code]int{BadInputData: 2, NotFound: 1}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"bad_input_data":2,"not_found":1}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+	var got map[Code]int
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[BadInputData] != 2 || got[NotFound] != 1 {
+		t.Errorf("json.Unmarshal = %v, want %v", got, m)
+	}
+}
+
+// TestCodeTextEncoders checks Code through encoders that, like YAML libraries,
+// rely on encoding.TextMarshaler and encoding.TextUnmarshaler.
+func TestCodeTextEncoders(t *testing.T) {
+	var _ encoding.TextMarshaler = Code(0)
+	var _ encoding.TextUnmarshaler = new(Code)
+
+	type config struct {
+		Code  Code   `xml:"code,attr"`
+		Codes []Code `xml:"retry"`
+	}
+	in := config{Code: PermissionDenied, Codes: []Code{Unavailable, Timeout}}
+	data, err := xml.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<config code="permission_denied"><retry>unavailable</retry><retry>timeout</retry></config>`
+	if string(data) != want {
+		t.Errorf("xml.Marshal = %s, want %s", data, want)
+	}
+	var out config
+	if err := xml.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Code != in.Code || len(out.Codes) != 2 || out.Codes[0] != Unavailable || out.Codes[1] != Timeout {
+		t.Errorf("xml.Unmarshal(%s) = %+v, want %+v", data, out, in)
+	}
+	if err := xml.Unmarshal([]byte(`<config code="nope"></config>`), &out); err == nil {
+		t.Error("xml.Unmarshal of an unknown code succeeded, want an error")
+	}
+}
+
+func TestWithFieldJSON(t *testing.T) {
+	base := ErrBadInput.WithField("email", "required")
+	a := base.WithField("age", "must be positive")
+	b := base.WithField("name", "too long")
+	if len(ErrBadInput.Fields) != 0 || len(base.Fields) != 1 {
+		t.Fatalf("WithField modified its receiver: %v, %v", ErrBadInput.Fields, base.Fields)
+	}
+	if a.Fields[1].Field != "age" || b.Fields[1].Field != "name" {
+		t.Errorf("chained copies share their fields: %v, %v", a.Fields, b.Fields)
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"message":"bad input data","code":"bad_input_data",` +
+		`"fields":[{"field":"email","message":"required"},{"field":"age","message":"must be positive"}]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+}
+
+func TestErrReasonJSON(t *testing.T) {
+	data, err := json.Marshal(NewErrWithReason(BadInputData, "input.missing_field", "name is required"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"message":"name is required","code":"bad_input_data","reason":"input.missing_field"}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+	data, _ = json.Marshal(NewErr(BadInputData, ""))
+	if strings.Contains(string(data), "reason") {
+		t.Errorf("json.Marshal without reason = %s, want no reason", data)
+	}
+	var e Err
+	if err := json.Unmarshal([]byte(`{"code":"bad_input_data","message":"x","reason":"input.range"}`), &e); err != nil || e.Reason != "input.range" {
+		t.Errorf("json.Unmarshal reason = %q, %v, want input.range", e.Reason, err)
+	}
+}
+
+func TestCodeBinary(t *testing.T) {
+	for _, c := range append(AllCodes(), Code(4294967295)) {
+		data, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 4 {
+			t.Errorf("Code(%d).MarshalBinary() = %x, want 4 bytes", c, data)
+		}
+		var got Code
+		if err := got.UnmarshalBinary(data); err != nil || got != c {
+			t.Errorf("UnmarshalBinary(%x) = %d, %v, want %d", data, got, err, c)
+		}
+	}
+	if data, _ := Unavailable.MarshalBinary(); !bytes.Equal(data, []byte{0, 0, 0, 14}) {
+		t.Errorf("Unavailable.MarshalBinary() = %x, want big-endian 0000000e", data)
+	}
+	var c Code
+	for _, data := range [][]byte{nil, {0, 1, 2}, {0, 0, 0, 0, 1}} {
+		if err := c.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary(%x) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestErrDetails(t *testing.T) {
+	type version struct {
+		ID      string `json:"id"`
+		Version int    `json:"version"`
+	}
+	e, err := ErrAlreadyExists.WithDetails(version{ID: "a", Version: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ErrAlreadyExists.Details != nil {
+		t.Error("WithDetails modified the sentinel")
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Err
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var got version
+	if err := json.Unmarshal(decoded.Details, &got); err != nil || got != (version{ID: "a", Version: 3}) {
+		t.Errorf("decoded details = %+v, %v, want the original struct", got, err)
+	}
+
+	own := NewErr(AlreadyExists, "")
+	if err := own.SetDetails(map[string]int{"version": 4}); err != nil || string(own.Details) != `{"version":4}` {
+		t.Errorf("SetDetails = %s, %v", own.Details, err)
+	}
+	if _, err := own.WithDetails(func() {}); err == nil {
+		t.Error("WithDetails(func) succeeded, want an error")
+	}
+
+	data, _ = json.Marshal(NewErr(AlreadyExists, ""))
+	if strings.Contains(string(data), "details") {
+		t.Errorf("json.Marshal without details = %s, want no details", data)
+	}
+}
+
+func TestMarshalCompact(t *testing.T) {
+	data, err := NewErr(NotFound, "").MarshalCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"code":"not_found"}`; string(data) != want {
+		t.Errorf("MarshalCompact with the default message = %s, want %s", data, want)
+	}
+	var e Err
+	if err := json.Unmarshal(data, &e); err != nil || e.Message != NotFound.String() {
+		t.Errorf("decoded compact message = %q, %v, want %q", e.Message, err, NotFound.String())
+	}
+
+	data, _ = NewErr(NotFound, "no such file").MarshalCompact()
+	if want := `{"code":"not_found","message":"no such file"}`; string(data) != want {
+		t.Errorf("MarshalCompact with a custom message = %s, want %s", data, want)
+	}
+
+	data, _ = json.Marshal(NewErr(NotFound, ""))
+	if want := `{"message":"resource not found","code":"not_found"}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want the full message %s", data, want)
+	}
+}
+
+func TestUseNumericCodes(t *testing.T) {
+	e := NewErr(NotFound, "gone")
+	marshal := func() string {
+		t.Helper()
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if got := marshal(); !strings.Contains(got, `"code":"not_found"`) {
+		t.Errorf("json.Marshal = %s, want the string code", got)
+	}
+
+	UseNumericCodes(true)
+	defer UseNumericCodes(false)
+	numeric := marshal()
+	if want := `"code":` + strconv.Itoa(int(NotFound)); !strings.Contains(numeric, want) {
+		t.Errorf("json.Marshal with numeric codes = %s, want it to contain %s", numeric, want)
+	}
+	var got Err
+	if err := json.Unmarshal([]byte(numeric), &got); err != nil || got.Code != NotFound {
+		t.Errorf("json.Unmarshal(%s) = %v, %v, want code %v", numeric, got.Code, err, NotFound)
+	}
+	data, err := json.Marshal(map[Code]int{NotFound: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"not_found":1}` {
+		t.Errorf("json.Marshal of a map with numeric codes = %s, want names as keys", data)
+	}
+
+	UseNumericCodes(false)
+	if got := marshal(); !strings.Contains(got, `"code":"not_found"`) {
+		t.Errorf("json.Marshal after UseNumericCodes(false) = %s, want the string code", got)
+	}
+}
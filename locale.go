@@ -0,0 +1,108 @@
+package codes
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// catalog holds the translated Code messages keyed by lower-cased
+// BCP 47 language tag.
+var catalog = struct {
+	sync.RWMutex
+	messages map[string]map[Code]string
+}{messages: map[string]map[Code]string{}}
+
+// RegisterMessages registers the translations of the Code messages for the
+// BCP 47 language tag lang (e.g. "es" or "pt-BR"), replacing any previously
+// registered message for the same code. It is meant to be called at startup.
+func RegisterMessages(lang string, m map[Code]string) {
+	lang = strings.ToLower(lang)
+	catalog.Lock()
+	defer catalog.Unlock()
+	msgs, ok := catalog.messages[lang]
+	if !ok {
+		msgs = map[Code]string{}
+		catalog.messages[lang] = msgs
+	}
+	for c, msg := range m {
+		msgs[c] = msg
+	}
+}
+
+// LocalizedString returns the message of the Code in the BCP 47 language lang.
+// If there is no translation for lang, its base language is tried
+// (e.g. "es" for "es-MX") and then the English String() is returned.
+// Unlike String, it never panics on undefined codes in strict mode.
+func (c Code) LocalizedString(lang string) string {
+	lang = strings.ToLower(lang)
+	catalog.RLock()
+	defer catalog.RUnlock()
+	for lang != "" {
+		if msg, ok := catalog.messages[lang][c]; ok {
+			return msg
+		}
+		i := strings.LastIndex(lang, "-")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	msg, _ := c.message()
+	return msg
+}
+
+// hasCatalog reports whether messages are registered for lang or its base language.
+func hasCatalog(lang string) bool {
+	lang = strings.ToLower(lang)
+	catalog.RLock()
+	defer catalog.RUnlock()
+	for lang != "" {
+		if _, ok := catalog.messages[lang]; ok {
+			return true
+		}
+		i := strings.LastIndex(lang, "-")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return false
+}
+
+// negotiateLanguage returns the language of the Accept-Language header value
+// with the highest quality that has a registered catalog, or "en" if none has.
+func negotiateLanguage(header string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		lang := strings.TrimSpace(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tag{lang, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	for _, t := range tags {
+		if hasCatalog(t.lang) {
+			return t.lang
+		}
+	}
+	return "en"
+}
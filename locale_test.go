@@ -0,0 +1,30 @@
+package codes
+
+import (
+	"testing"
+)
+
+func TestLocalizedString(t *testing.T) {
+	RegisterMessages("es", map[Code]string{
+		NotFound:     "no encontrado",
+		BadInputData: "datos de entrada incorrectos",
+	})
+	tests := []struct {
+		code Code
+		lang string
+		want string
+	}{
+		{NotFound, "es", "no encontrado"},
+		{NotFound, "ES", "no encontrado"},
+		{NotFound, "es-MX", "no encontrado"},
+		{Internal, "es", Internal.String()},
+		{NotFound, "en", NotFound.String()},
+		{NotFound, "fr", NotFound.String()},
+		{NotFound, "", NotFound.String()},
+	}
+	for _, tt := range tests {
+		if got := tt.code.LocalizedString(tt.lang); got != tt.want {
+			t.Errorf("%v.LocalizedString(%q) = %q, want %q", tt.code.Name(), tt.lang, got, tt.want)
+		}
+	}
+}
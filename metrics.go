@@ -0,0 +1,57 @@
+package codes
+
+import "sync"
+
+// maxMetricLabelLen bounds the length of the values returned by MetricLabel.
+const maxMetricLabelLen = 64
+
+// MetricLabel returns a stable, low-cardinality identifier of the Code suitable
+// as a Prometheus label value. It is the Name of the code restricted to ASCII
+// letters, digits and underscores and bounded to 64 characters. Undefined codes
+// collapse to "unknown" so values coming from untrusted input cannot explode
+// the number of label values.
+func (c Code) MetricLabel() string {
+	name := c.Name()
+	label := make([]byte, 0, len(name))
+	for i := 0; i < len(name) && len(label) < maxMetricLabelLen; i++ {
+		b := name[i]
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9', b == '_':
+			label = append(label, b)
+		default:
+			label = append(label, '_')
+		}
+	}
+	return string(label)
+}
+
+// A MetricsSink receives a notification for every Err created with New or NewErr,
+// so per-code counters can be kept without this package importing a metrics library.
+type MetricsSink interface {
+	IncError(code Code)
+}
+
+// metricsSink holds the MetricsSink set with SetMetricsSink.
+var metricsSink = struct {
+	sync.RWMutex
+	sink MetricsSink
+}{}
+
+// SetMetricsSink sets the MetricsSink notified when errors are created.
+// A nil sink disables the notifications. The setting is global and is meant
+// to be applied at startup.
+func SetMetricsSink(s MetricsSink) {
+	metricsSink.Lock()
+	metricsSink.sink = s
+	metricsSink.Unlock()
+}
+
+// incError notifies the MetricsSink, if any, that an error with code c was created.
+func incError(c Code) {
+	metricsSink.RLock()
+	s := metricsSink.sink
+	metricsSink.RUnlock()
+	if s != nil {
+		s.IncError(c)
+	}
+}
@@ -0,0 +1,60 @@
+package codes
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMetricLabel(t *testing.T) {
+	for _, c := range AllCodes() {
+		if got := c.MetricLabel(); got != c.Name() {
+			t.Errorf("%v.MetricLabel() = %q, want %q", c.Name(), got, c.Name())
+		}
+	}
+	for _, c := range []Code{Code(999), Code(1 << 30), Code(4294967295)} {
+		if got := c.MetricLabel(); got != "unknown" {
+			t.Errorf("Code(%d).MetricLabel() = %q, want unknown", c, got)
+		}
+	}
+}
+
+// countingSink is a MetricsSink counting the errors of each code.
+type countingSink struct {
+	mu     sync.Mutex
+	counts map[Code]int
+}
+
+func (s *countingSink) IncError(code Code) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = map[Code]int{}
+	}
+	s.counts[code]++
+}
+
+func TestSetMetricsSink(t *testing.T) {
+	sink := &countingSink{}
+	SetMetricsSink(sink)
+	defer SetMetricsSink(nil)
+
+	NewErr(NotFound, "")
+	New(NotFound, WithMessage("gone"))
+	NewErrf(BadInputData, "field %s", "name")
+	NewErr(Code(987654), "")
+	want := map[Code]int{NotFound: 2, BadInputData: 1, Internal: 1}
+	for code, n := range want {
+		if sink.counts[code] != n {
+			t.Errorf("IncError(%v) called %d times, want %d", code, sink.counts[code], n)
+		}
+	}
+	if len(sink.counts) != len(want) {
+		t.Errorf("IncError called for %v, want only %v", sink.counts, want)
+	}
+
+	SetMetricsSink(nil)
+	NewErr(NotFound, "")
+	if sink.counts[NotFound] != 2 {
+		t.Errorf("IncError called after SetMetricsSink(nil)")
+	}
+}
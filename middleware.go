@@ -0,0 +1,158 @@
+package codes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// headerCode is the response header that carries the Name of the Code
+// written by WriteError when the CodeHeader middleware is used.
+const headerCode = "X-Clawio-Code"
+
+// errorWriteHook is implemented by the http.ResponseWriter wrappers of the
+// middlewares in this package that need to know when WriteError runs.
+// beforeWriteError returns the Err to write, which may differ from e.
+type errorWriteHook interface {
+	beforeWriteError(e *Err) *Err
+}
+
+// notifyWriteError calls the errorWriteHooks found in the chain of wrappers of w,
+// which is followed through their Unwrap method, before e is written.
+// It returns the Err to write.
+func notifyWriteError(w http.ResponseWriter, e *Err) *Err {
+	for w != nil {
+		if h, ok := w.(errorWriteHook); ok {
+			e = h.beforeWriteError(e)
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return e
+}
+
+// codeHeaderWriter is the http.ResponseWriter used by CodeHeader.
+type codeHeaderWriter struct {
+	http.ResponseWriter
+}
+
+func (w *codeHeaderWriter) beforeWriteError(e *Err) *Err {
+	w.Header().Set(headerCode, e.Code.Name())
+	return e
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController.
+func (w *codeHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// CodeHeader is a middleware that makes WriteError set the X-Clawio-Code
+// response header to the Name of the written Code, so proxies can route
+// and log by code without parsing bodies. Successful responses are untouched.
+func CodeHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&codeHeaderWriter{w}, r)
+	})
+}
+
+// errorBodyWriter is the http.ResponseWriter used by ErrorBody.
+type errorBodyWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	handled     bool // WriteError is writing the response
+	intercepted bool // the body is being replaced
+	status      int
+}
+
+func (w *errorBodyWriter) beforeWriteError(e *Err) *Err {
+	w.handled = true
+	return e
+}
+
+// WriteHeader implements the http.ResponseWriter interface.
+// 5xx statuses without a JSON body are held back until the handler returns.
+func (w *errorBodyWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	contentType := w.Header().Get("Content-Type")
+	if status >= 500 && !w.handled && !strings.HasPrefix(contentType, "application/json") {
+		w.intercepted = true
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements the http.ResponseWriter interface.
+// Writes are discarded when the body is being replaced.
+func (w *errorBodyWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.intercepted {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController.
+func (w *errorBodyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ErrorBody is a middleware that guarantees that 5xx responses carry the JSON
+// error envelope, even when they are written by code that does not use WriteError,
+// e.g. a misbehaving library. If next writes a 5xx status without a JSON body,
+// its body is replaced by the envelope of the Code derived from the status with
+// CodeFromHTTPStatus, keeping the original status. Responses written by WriteError
+// are left untouched.
+func ErrorBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ew := &errorBodyWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		if !ew.intercepted {
+			return
+		}
+		e := notifyWriteError(w, NewErr(CodeFromHTTPStatus(ew.status), ""))
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Type", "application/json")
+		w.WriteHeader(ew.status)
+		json.NewEncoder(w).Encode(errorResponseJSON{Err: e, RequestID: h.Get(headerRequestID)})
+	})
+}
+
+// localeWriter is the http.ResponseWriter used by Localize.
+type localeWriter struct {
+	http.ResponseWriter
+	lang string
+}
+
+func (w *localeWriter) beforeWriteError(e *Err) *Err {
+	if msg, _ := e.Code.message(); e.Message != msg {
+		return e
+	}
+	return e.WithMessage(e.Code.LocalizedString(w.lang))
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController.
+func (w *localeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Localize is a middleware that makes WriteError translate the message of the
+// Err to the language preferred by the Accept-Language header of the request,
+// using the catalogs registered with RegisterMessages. Only default code messages
+// are translated, and English is used for unsupported languages. The code on the
+// wire is not affected.
+func Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := negotiateLanguage(r.Header.Get("Accept-Language"))
+		next.ServeHTTP(&localeWriter{ResponseWriter: w, lang: lang}, r)
+	})
+}
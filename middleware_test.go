@@ -0,0 +1,125 @@
+package codes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCodeHeader(t *testing.T) {
+	h := CodeHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			w.Write([]byte("hello"))
+			return
+		}
+		Abort(w, r, PermissionDenied, "")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secret", nil))
+	if got := rec.Header().Get("X-Clawio-Code"); got != "permission_denied" {
+		t.Errorf("X-Clawio-Code = %q, want permission_denied", got)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if _, ok := rec.Header()["X-Clawio-Code"]; ok {
+		t.Errorf("X-Clawio-Code is set on success")
+	}
+}
+
+func TestErrorBody(t *testing.T) {
+	h := ErrorBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bare":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("<h1>oops</h1>"))
+		case "/envelope":
+			WriteError(w, r, NewErr(Unavailable, "down for maintenance"))
+		case "/ok":
+			w.Write([]byte("hello"))
+		}
+	}))
+
+	tests := []struct {
+		path   string
+		status int
+		code   Code
+	}{
+		{"/bare", http.StatusServiceUnavailable, Unavailable},
+		{"/html", http.StatusInternalServerError, Internal},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type = %q, want application/json", tt.path, got)
+		}
+		if e := decodeRecorded(t, rec); e.Code != tt.code {
+			t.Errorf("%s: code = %v, want %v", tt.path, e.Code, tt.code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/envelope", nil))
+	if n := strings.Count(rec.Body.String(), `"error"`); n != 1 {
+		t.Errorf("envelope written %d times in %q", n, rec.Body.String())
+	}
+	if e := decodeRecorded(t, rec); e.Code != Unavailable || e.Message != "down for maintenance" {
+		t.Errorf("envelope = %v, want the one written by WriteError", e)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Errorf("successful response = %d %q, want 200 \"hello\"", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	RegisterMessages("es", map[Code]string{NotFound: "no encontrado"})
+	h := Localize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/custom" {
+			Abort(w, r, NotFound, "no such file")
+			return
+		}
+		Abort(w, r, NotFound, "")
+	}))
+
+	tests := []struct {
+		path, lang string
+		want       string
+	}{
+		{"/", "es", "no encontrado"},
+		{"/", "es-AR,en;q=0.5", "no encontrado"},
+		{"/", "fr;q=0.9,es;q=0.8", "no encontrado"},
+		{"/", "fr", NotFound.String()},
+		{"/", "", NotFound.String()},
+		{"/custom", "es", "no such file"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.lang != "" {
+			req.Header.Set("Accept-Language", tt.lang)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		e := decodeRecorded(t, rec)
+		if e.Message != tt.want {
+			t.Errorf("%s with Accept-Language %q: message = %q, want %q", tt.path, tt.lang, e.Message, tt.want)
+		}
+		if e.Code != NotFound {
+			t.Errorf("%s with Accept-Language %q: code = %v, want %v", tt.path, tt.lang, e.Code, NotFound)
+		}
+	}
+}
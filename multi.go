@@ -0,0 +1,95 @@
+package codes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A MultiError reports several errors at once, e.g. the failures of
+// a batch operation.
+type MultiError struct {
+	Errors []*Err `json:"errors"`
+}
+
+// Add appends e to the MultiError. Nil errors are ignored.
+func (m *MultiError) Add(e *Err) {
+	if e == nil {
+		return
+	}
+	m.Errors = append(m.Errors, e)
+}
+
+// ErrorOrNil returns the MultiError as an error, or nil if it holds no errors.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error() implements the Error interface.
+func (m *MultiError) Error() string {
+	ids := make([]string, 0, len(m.Errors))
+	for _, e := range m.Errors {
+		ids = append(ids, e.Code.Name())
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(m.Errors), strings.Join(ids, ", "))
+}
+
+// categoryRank orders the categories from the least to the most severe.
+var categoryRank = map[Category]int{
+	CategorySuccess: 0,
+	CategoryClient:  1,
+	CategoryAuth:    2,
+	CategoryServer:  3,
+}
+
+// Worst returns the most severe error of the MultiError: server errors are worse
+// than auth errors, which are worse than client errors, which are worse than
+// Success. Ties resolve to the first error added. It returns nil if the
+// MultiError holds no errors.
+func (m *MultiError) Worst() *Err {
+	var worst *Err
+	for _, e := range m.Errors {
+		if worst == nil || categoryRank[e.Code.Category()] > categoryRank[worst.Code.Category()] {
+			worst = e
+		}
+	}
+	return worst
+}
+
+// A CodeCollector records the first error of each Code, e.g. to aggregate the
+// partial failures of goroutines in a fan-out. It is safe for concurrent use
+// and its zero value is ready to use.
+type CodeCollector struct {
+	mu   sync.Mutex
+	errs map[Code]*Err
+}
+
+// Record records e unless an error with the same Code was already recorded.
+// Nil errors are ignored.
+func (c *CodeCollector) Record(e *Err) {
+	if e == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.errs == nil {
+		c.errs = map[Code]*Err{}
+	}
+	if _, ok := c.errs[e.Code]; !ok {
+		c.errs[e.Code] = e
+	}
+}
+
+// Snapshot returns a copy of the recorded errors, keyed by Code.
+func (c *CodeCollector) Snapshot() map[Code]*Err {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[Code]*Err, len(c.errs))
+	for code, e := range c.errs {
+		snapshot[code] = e
+	}
+	return snapshot
+}
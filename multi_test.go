@@ -0,0 +1,112 @@
+package codes
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestMultiError(t *testing.T) {
+	var m MultiError
+	if err := m.ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() of an empty MultiError = %v, want nil", err)
+	}
+	m.Add(NewErr(NotFound, "a"))
+	m.Add(nil)
+	m.Add(NewErr(BadInputData, "b"))
+	if len(m.Errors) != 2 {
+		t.Fatalf("%d errors, want 2", len(m.Errors))
+	}
+	err := m.ErrorOrNil()
+	if err == nil {
+		t.Fatal("ErrorOrNil() = nil, want the MultiError")
+	}
+	if want := "2 errors occurred: not_found, bad_input_data"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var nilMulti *MultiError
+	if nilMulti.ErrorOrNil() != nil {
+		t.Error("ErrorOrNil() of a nil MultiError != nil")
+	}
+}
+
+func TestMultiErrorJSON(t *testing.T) {
+	m := &MultiError{}
+	m.Add(NewErr(NotFound, "a"))
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"errors":[{"message":"a","code":"not_found"}]}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+}
+
+func TestMultiErrorWorst(t *testing.T) {
+	var m MultiError
+	if m.Worst() != nil {
+		t.Error("Worst() of an empty MultiError != nil")
+	}
+	client := NewErr(BadInputData, "client")
+	auth := NewErr(Unauthenticated, "auth")
+	server := NewErr(Unavailable, "first server")
+	m.Add(client)
+	if m.Worst() != client {
+		t.Errorf("Worst() = %v, want %v", m.Worst(), client)
+	}
+	m.Add(auth)
+	if m.Worst() != auth {
+		t.Errorf("Worst() = %v, want %v", m.Worst(), auth)
+	}
+	m.Add(server)
+	m.Add(NewErr(Internal, "second server"))
+	m.Add(NewErr(NotFound, "client"))
+	if m.Worst() != server {
+		t.Errorf("Worst() = %v, want the first server error", m.Worst())
+	}
+}
+
+func TestCodeCollector(t *testing.T) {
+	var c CodeCollector
+	first := NewErr(NotFound, "first")
+	c.Record(first)
+	c.Record(NewErr(NotFound, "second"))
+	c.Record(nil)
+	c.Record(NewErr(Internal, ""))
+
+	got := c.Snapshot()
+	if len(got) != 2 {
+		t.Fatalf("Snapshot() has %d errors, want 2", len(got))
+	}
+	if got[NotFound] != first {
+		t.Errorf("Snapshot()[NotFound] = %v, want the first recorded %v", got[NotFound], first)
+	}
+	delete(got, NotFound)
+	if _, ok := c.Snapshot()[NotFound]; !ok {
+		t.Error("modifying the snapshot changed the collector")
+	}
+}
+
+func TestCodeCollectorConcurrent(t *testing.T) {
+	var c CodeCollector
+	codes := []Code{NotFound, Internal, Unavailable, BadInputData}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Record(NewErr(codes[i%len(codes)], ""))
+			c.Snapshot()
+		}(i)
+	}
+	wg.Wait()
+	got := c.Snapshot()
+	if len(got) != len(codes) {
+		t.Fatalf("Snapshot() has %d errors, want %d", len(got), len(codes))
+	}
+	for _, code := range codes {
+		if e := got[code]; e == nil || e.Code != code {
+			t.Errorf("Snapshot()[%v] = %v, want an error with that code", code, e)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package codes
+
+import "sync/atomic"
+
+// OpenAPIErrorSchema returns a JSON schema fragment, suitable for an OpenAPI
+// specification, describing the {"error":{...}} envelope produced by this package.
+// The code property enumerates every code returned by AllCodes followed by the
+// codes added with Register, as their Name, or as integers if UseNumericCodes
+// is enabled. The schema reflects the settings at the time of the call, so it
+// should be generated after the codes are registered.
+func OpenAPIErrorSchema() map[string]interface{} {
+	all := append(AllCodes(), customCodes()...)
+	var code map[string]interface{}
+	if atomic.LoadInt32(&numericCodes) == 1 {
+		enum := make([]uint32, 0, len(all))
+		for _, c := range all {
+			enum = append(enum, uint32(c))
+		}
+		code = map[string]interface{}{"type": "integer", "enum": enum}
+	} else {
+		enum := make([]string, 0, len(all))
+		for _, c := range all {
+			enum = append(enum, c.Name())
+		}
+		code = map[string]interface{}{"type": "string", "enum": enum}
+	}
+	str := func() map[string]interface{} {
+		return map[string]interface{}{"type": "string"}
+	}
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"error"},
+		"properties": map[string]interface{}{
+			"request_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID to correlate the error with server logs",
+			},
+			"error": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"message", "code"},
+				"properties": map[string]interface{}{
+					"message": str(),
+					"code":    code,
+					"retry_after": map[string]interface{}{
+						"type":        "integer",
+						"minimum":     0,
+						"description": "seconds to wait before retrying",
+					},
+					"reason": map[string]interface{}{
+						"type":        "string",
+						"description": "machine-readable sub-code refining code",
+					},
+					"doc_url": map[string]interface{}{
+						"type":        "string",
+						"format":      "uri",
+						"description": "documentation of the code",
+					},
+					"details": map[string]interface{}{
+						"description": "arbitrary structured context",
+					},
+					"fields": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type":     "object",
+							"required": []string{"field", "message"},
+							"properties": map[string]interface{}{
+								"field":   str(),
+								"message": str(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,99 @@
+package codes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPIErrorSchema(t *testing.T) {
+	data, err := json.Marshal(OpenAPIErrorSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties struct {
+			Error struct {
+				Required   []string `json:"required"`
+				Properties struct {
+					Code struct {
+						Enum []string `json:"enum"`
+					} `json:"code"`
+				} `json:"properties"`
+			} `json:"error"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid schema %s: %v", data, err)
+	}
+	enum := map[string]bool{}
+	for _, name := range schema.Properties.Error.Properties.Code.Enum {
+		enum[name] = true
+	}
+	for _, c := range AllCodes() {
+		if !enum[c.Name()] {
+			t.Errorf("the code enum lacks %q", c.Name())
+		}
+	}
+	if len(enum) != len(AllCodes()) {
+		t.Errorf("the code enum has %d names, want %d", len(enum), len(AllCodes()))
+	}
+
+	// every property of a serialized Err must be described
+	props := OpenAPIErrorSchema()["properties"].(map[string]interface{})["error"].(map[string]interface{})["properties"].(map[string]interface{})
+	SetDocBaseURL("https://docs.example.com")
+	defer SetDocBaseURL("")
+	e := NewValidationErr(FieldError{Field: "a", Message: "b"})
+	e.RetryAfter = 1
+	e.Reason = "input.invalid"
+	e.Details = json.RawMessage(`{}`)
+	data, _ = json.Marshal(e)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	for name := range fields {
+		if _, ok := props[name]; !ok {
+			t.Errorf("the schema lacks the %q property", name)
+		}
+	}
+}
+
+// codeSchema returns the schema of the code property of OpenAPIErrorSchema.
+func codeSchema() map[string]interface{} {
+	props := OpenAPIErrorSchema()["properties"].(map[string]interface{})["error"].(map[string]interface{})["properties"].(map[string]interface{})
+	return props["code"].(map[string]interface{})
+}
+
+func TestOpenAPIErrorSchemaCustomCodes(t *testing.T) {
+	restoreRegistry(t)
+	if err := Register(3000, "test_openapi", ""); err != nil {
+		t.Fatal(err)
+	}
+	enum := codeSchema()["enum"].([]string)
+	if got := enum[len(enum)-1]; got != "test_openapi" {
+		t.Errorf("last code of the enum = %q, want the registered test_openapi", got)
+	}
+}
+
+func TestOpenAPIErrorSchemaNumericCodes(t *testing.T) {
+	UseNumericCodes(true)
+	defer UseNumericCodes(false)
+	schema := codeSchema()
+	if schema["type"] != "integer" {
+		t.Errorf("code type with numeric codes = %v, want integer", schema["type"])
+	}
+	enum := schema["enum"].([]uint32)
+	if len(enum) != len(AllCodes()) {
+		t.Fatalf("the code enum has %d values, want %d", len(enum), len(AllCodes()))
+	}
+	for i, c := range AllCodes() {
+		if enum[i] != uint32(c) {
+			t.Errorf("enum[%d] = %d, want %d", i, enum[i], c)
+		}
+	}
+	data, _ := json.Marshal(NewErr(NotFound, ""))
+	var v struct {
+		Code json.Number `json:"code"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("json.Marshal = %s, want an integer code: %v", data, err)
+	}
+}
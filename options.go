@@ -0,0 +1,103 @@
+package codes
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultMaxMessageLength is the default value of maxMessageLength.
+const defaultMaxMessageLength = 1 << 10
+
+// maxMessageLength is the maximum length in bytes of an Err message.
+// It is accessed atomically.
+var maxMessageLength int64 = defaultMaxMessageLength
+
+// SetMaxMessageLength sets the maximum length in bytes of the messages of the
+// errors created with New, NewErr and WithMessage; longer messages are truncated.
+// The default is 1 KiB; n <= 0 restores it. The setting is global and is meant
+// to be applied at startup.
+func SetMaxMessageLength(n int64) {
+	if n <= 0 {
+		n = defaultMaxMessageLength
+	}
+	atomic.StoreInt64(&maxMessageLength, n)
+}
+
+// sanitizeMessage makes msg safe for serialization and log pipelines, since it
+// may contain attacker-controlled input: invalid UTF-8 is replaced with the
+// Unicode replacement character and the message is truncated, on a rune boundary,
+// to the maximum message length.
+func sanitizeMessage(msg string) string {
+	msg = strings.ToValidUTF8(msg, string(utf8.RuneError))
+	max := int(atomic.LoadInt64(&maxMessageLength))
+	if len(msg) <= max {
+		return msg
+	}
+	for max > 0 && !utf8.RuneStart(msg[max]) {
+		max--
+	}
+	return msg[:max]
+}
+
+// An Option configures an Err created with New.
+type Option func(*Err)
+
+// New creates an Err with the given Code, configured by opts.
+// If no message is set, the default code message will be used.
+// The message is sanitized: see SetMaxMessageLength.
+// Codes that are not valid are normalized to Internal, or make New panic
+// in strict mode (see SetStrict).
+// The MetricsSink set with SetMetricsSink, if any, is notified.
+func New(c Code, opts ...Option) *Err {
+	if !c.Valid() {
+		checkStrict(c)
+		c = Internal
+	}
+	e := &Err{Code: c}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.Message == "" {
+		e.Message = c.String()
+	}
+	e.Message = sanitizeMessage(e.Message)
+	incError(c)
+	return e
+}
+
+// WithMessage sets the message of the Err.
+func WithMessage(msg string) Option {
+	return func(e *Err) {
+		e.Message = msg
+	}
+}
+
+// WithCause sets the underlying cause of the Err.
+func WithCause(cause error) Option {
+	return func(e *Err) {
+		e.cause = cause
+	}
+}
+
+// WithField appends a FieldError to the Fields of the Err.
+func WithField(field, msg string) Option {
+	return func(e *Err) {
+		e.Fields = append(e.Fields, FieldError{Field: field, Message: msg})
+	}
+}
+
+// WithReason sets the Reason of the Err.
+func WithReason(reason string) Option {
+	return func(e *Err) {
+		e.Reason = reason
+	}
+}
+
+// WithRetryAfter sets the RetryAfter of the Err.
+func WithRetryAfter(d time.Duration) Option {
+	return func(e *Err) {
+		e.RetryAfter = d
+	}
+}
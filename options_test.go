@@ -0,0 +1,71 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestNew(t *testing.T) {
+	cause := errors.New("disk full")
+	e := New(TooBig,
+		WithMessage("upload too big"),
+		WithCause(cause),
+		WithField("file", "over 1 GiB"),
+		WithField("name", "too long"),
+		WithReason("upload.size"),
+		WithRetryAfter(time.Minute))
+	if e.Code != TooBig || e.Message != "upload too big" || e.Reason != "upload.size" || e.RetryAfter != time.Minute {
+		t.Errorf("New = %+v", e)
+	}
+	if len(e.Fields) != 2 || e.Fields[1].Field != "name" {
+		t.Errorf("Fields = %v, want two fields in order", e.Fields)
+	}
+	if !errors.Is(e, cause) {
+		t.Error("New did not set the cause")
+	}
+
+	if e := New(NotFound); e.Message != NotFound.String() {
+		t.Errorf("New without options = %q, want the default message", e.Message)
+	}
+	if e := NewErr(NotFound, "x"); !e.Equal(New(NotFound, WithMessage("x"))) {
+		t.Errorf("NewErr = %v, want the same as New", e)
+	}
+}
+
+func TestMessageSanitized(t *testing.T) {
+	long := strings.Repeat("a", 2000)
+	if got := NewErr(Internal, long).Message; len(got) != 1024 {
+		t.Errorf("len(NewErr(long).Message) = %d, want 1024", len(got))
+	}
+	if got := New(Internal, WithMessage(long)).Message; len(got) != 1024 {
+		t.Errorf("len(New(WithMessage(long)).Message) = %d, want 1024", len(got))
+	}
+	if got := NewErr(NotFound, "").WithMessage(long).Message; len(got) != 1024 {
+		t.Errorf("len(WithMessage(long).Message) = %d, want 1024", len(got))
+	}
+
+	if got := NewErr(BadInputData, "bad \xff\xfe name").Message; got != "bad � name" {
+		t.Errorf("NewErr(invalid UTF-8).Message = %q, want %q", got, "bad � name")
+	}
+
+	SetMaxMessageLength(5)
+	defer SetMaxMessageLength(0)
+	// "ñ" is 2 bytes long and must not be split
+	if got := NewErr(Internal, "abcdñ").Message; got != "abcd" {
+		t.Errorf("NewErr truncated at 5 bytes = %q, want %q", got, "abcd")
+	}
+	if got := NewErr(Internal, "abcde").Message; got != "abcde" {
+		t.Errorf("NewErr(message of the maximum length) = %q, want it unchanged", got)
+	}
+	if got := NewErr(Internal, strings.Repeat("é", 10)).Message; !utf8.ValidString(got) || len(got) > 5 {
+		t.Errorf("NewErr truncated = %q, want valid UTF-8 of at most 5 bytes", got)
+	}
+
+	SetMaxMessageLength(0)
+	if got := NewErr(Internal, long).Message; len(got) != defaultMaxMessageLength {
+		t.Errorf("len(Message) after SetMaxMessageLength(0) = %d, want %d", len(got), defaultMaxMessageLength)
+	}
+}
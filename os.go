@@ -0,0 +1,25 @@
+package codes
+
+import (
+	"errors"
+	"os"
+)
+
+// FromOSError converts an error returned by the os package into an Err:
+// os.ErrNotExist becomes NotFound, os.ErrPermission becomes PermissionDenied,
+// os.ErrExist becomes AlreadyExists and any other error becomes Internal.
+// The original error is kept as the cause. A nil error yields a nil Err.
+func FromOSError(err error) *Err {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		return Wrap(NotFound, err)
+	case errors.Is(err, os.ErrPermission):
+		return Wrap(PermissionDenied, err)
+	case errors.Is(err, os.ErrExist):
+		return Wrap(AlreadyExists, err)
+	default:
+		return Wrap(Internal, err)
+	}
+}
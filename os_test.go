@@ -0,0 +1,38 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestFromOSError(t *testing.T) {
+	other := errors.New("disk on fire")
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{os.ErrNotExist, NotFound},
+		{&fs.PathError{Op: "open", Path: "/x", Err: fs.ErrNotExist}, NotFound},
+		{os.ErrPermission, PermissionDenied},
+		{fmt.Errorf("mkdir: %w", os.ErrExist), AlreadyExists},
+		{other, Internal},
+	}
+	for _, tt := range tests {
+		e := FromOSError(tt.err)
+		if e.Code != tt.want {
+			t.Errorf("FromOSError(%v).Code = %v, want %v", tt.err, e.Code, tt.want)
+		}
+		if !errors.Is(e, tt.err) {
+			t.Errorf("FromOSError(%v) does not wrap the original error", tt.err)
+		}
+	}
+	if _, err := os.Open("/nonexistent/clawio/file"); FromOSError(err).Code != NotFound {
+		t.Errorf("FromOSError(os.Open of a missing file).Code = %v, want %v", FromOSError(err).Code, NotFound)
+	}
+	if e := FromOSError(nil); e != nil {
+		t.Errorf("FromOSError(nil) = %v, want nil", e)
+	}
+}
@@ -0,0 +1,63 @@
+package codes
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// problemJSON is the RFC 7807 representation of an Err.
+type problemJSON struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     Code   `json:"code"`
+}
+
+// ToProblemJSON encodes e as RFC 7807 problem details for the given instance URI.
+// The type is the DocURL of the code, or its Name if no documentation base URL is
+// set, the title is the default code message, the status is e.Code.HTTPStatus()
+// and the detail is the message of e. The code is also included as an extension member.
+func (e *Err) ToProblemJSON(instance string) ([]byte, error) {
+	typ := e.Code.DocURL()
+	if typ == "" {
+		typ = e.Code.Name()
+	}
+	title, _ := e.Code.message()
+	return json.Marshal(problemJSON{
+		Type:     typ,
+		Title:    title,
+		Status:   e.Code.HTTPStatus(),
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+	})
+}
+
+// ParseProblemJSON decodes RFC 7807 problem details into an Err, the reverse of
+// ToProblemJSON. The code is taken from the code extension member if present,
+// then from the type, and is otherwise derived from the status, which is also
+// used for undefined codes so they never panic in strict mode.
+// It also returns the instance URI of the problem.
+func ParseProblemJSON(data []byte) (*Err, string, error) {
+	var v struct {
+		problemJSON
+		Code *Code `json:"code"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, "", err
+	}
+	var c Code
+	if v.Code != nil && v.Code.Valid() {
+		c = *v.Code
+	} else if parsed, err := ParseCode(v.Type[strings.LastIndex(v.Type, "/")+1:]); err == nil {
+		c = parsed
+	} else {
+		c = CodeFromHTTPStatus(v.Status)
+	}
+	return NewErr(c, v.Detail), v.Instance, nil
+}
@@ -0,0 +1,73 @@
+package codes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToProblemJSON(t *testing.T) {
+	data, err := NewErr(NotFound, "no file at /a").ToProblemJSON("/files/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p map[string]interface{}
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":     "not_found",
+		"title":    NotFound.String(),
+		"status":   float64(404),
+		"detail":   "no file at /a",
+		"instance": "/files/a",
+		"code":     "not_found",
+	}
+	if len(p) != len(want) {
+		t.Errorf("ToProblemJSON = %s, want the RFC 7807 members and code", data)
+	}
+	for k, v := range want {
+		if p[k] != v {
+			t.Errorf("%s = %v, want %v", k, p[k], v)
+		}
+	}
+
+	SetDocBaseURL("https://docs.example.com")
+	defer SetDocBaseURL("")
+	data, _ = NewErr(NotFound, "").ToProblemJSON("")
+	json.Unmarshal(data, &p)
+	if p["type"] != "https://docs.example.com/not_found" {
+		t.Errorf("type = %v, want the doc URL", p["type"])
+	}
+}
+
+func TestParseProblemJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Code
+	}{
+		{`{"type":"x","title":"t","status":400,"code":"too_big","detail":"d","instance":"/i"}`, TooBig},
+		{`{"type":"https://docs.example.com/errors/not_found","status":400}`, NotFound},
+		{`{"type":"about:blank","status":409}`, AlreadyExists},
+		{`{"type":"about:blank","status":503,"code":99999}`, Unavailable},
+	}
+	for _, tt := range tests {
+		e, _, err := ParseProblemJSON([]byte(tt.in))
+		if err != nil {
+			t.Errorf("ParseProblemJSON(%s): %v", tt.in, err)
+			continue
+		}
+		if e.Code != tt.want {
+			t.Errorf("ParseProblemJSON(%s) = %v, want %v", tt.in, e.Code.Name(), tt.want.Name())
+		}
+	}
+
+	orig := NewErr(PermissionDenied, "not yours")
+	data, _ := orig.ToProblemJSON("/files/b")
+	e, instance, err := ParseProblemJSON(data)
+	if err != nil || !e.Equal(orig) || instance != "/files/b" {
+		t.Errorf("round trip = %v, %q, %v, want %v", e, instance, err, orig)
+	}
+	if _, _, err := ParseProblemJSON([]byte("nope")); err == nil {
+		t.Error("ParseProblemJSON(garbage) succeeded")
+	}
+}
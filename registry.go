@@ -0,0 +1,194 @@
+package codes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MinCustomCode is the lowest value that can be used for an
+// application-specific code. Values below it are reserved for the
+// codes defined by this package.
+const MinCustomCode Code = 1000
+
+type customCode struct {
+	name    string
+	message string
+}
+
+// A codeRange is a range of custom codes reserved by an owner.
+type codeRange struct {
+	owner  string
+	lo, hi Code
+}
+
+func (r codeRange) contains(c Code) bool {
+	return r.lo <= c && c <= r.hi
+}
+
+// registry holds the application-specific codes added with Register
+// and the ranges reserved with ReserveRange.
+var registry = struct {
+	sync.RWMutex
+	codes  map[Code]customCode
+	byName map[string]Code
+	ranges []codeRange
+}{codes: map[Code]customCode{}, byName: map[string]Code{}}
+
+// ReserveRange reserves the custom codes from lo to hi, both included, for owner,
+// e.g. a subsystem or a team. Codes inside a reserved range can only be registered
+// with RegisterOwned by the same owner, which prevents two owners from colliding.
+// The range must be above MinCustomCode and must not overlap other reservations
+// or contain codes that are already registered.
+func ReserveRange(owner string, lo, hi Code) error {
+	if owner == "" {
+		return fmt.Errorf("codes: empty range owner")
+	}
+	if lo < MinCustomCode || hi < lo {
+		return fmt.Errorf("codes: invalid range [%d, %d], custom codes start at %d", lo, hi, MinCustomCode)
+	}
+	r := codeRange{owner: owner, lo: lo, hi: hi}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for _, other := range registry.ranges {
+		if r.lo <= other.hi && other.lo <= r.hi {
+			return fmt.Errorf("codes: range [%d, %d] overlaps range [%d, %d] of %q", lo, hi, other.lo, other.hi, other.owner)
+		}
+	}
+	for c := range registry.codes {
+		if r.contains(c) {
+			return fmt.Errorf("codes: range [%d, %d] contains the registered code %d", lo, hi, c)
+		}
+	}
+	registry.ranges = append(registry.ranges, r)
+	return nil
+}
+
+// Register adds an application-specific code with the given snake-case name
+// and default message, so that String(), ParseCode and the marshaling methods
+// know about it. Custom codes must be greater or equal than MinCustomCode and
+// both the code and the name must be unique. Codes inside a range reserved with
+// ReserveRange must be registered with RegisterOwned instead. Register is safe
+// for concurrent use, although it is meant to be called at startup.
+func Register(c Code, name, message string) error {
+	return register("", c, name, message)
+}
+
+// RegisterOwned works like Register for a code inside a range reserved by owner
+// with ReserveRange. It fails if the code is outside the ranges of owner.
+func RegisterOwned(owner string, c Code, name, message string) error {
+	if owner == "" {
+		return fmt.Errorf("codes: empty range owner")
+	}
+	return register(owner, c, name, message)
+}
+
+func register(owner string, c Code, name, message string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if c < MinCustomCode {
+		return fmt.Errorf("codes: code %d is inside the reserved range, custom codes start at %d", c, MinCustomCode)
+	}
+	if name == "" {
+		return fmt.Errorf("codes: code %d has an empty name", c)
+	}
+	if _, err := ParseCode(name); err == nil {
+		return fmt.Errorf("codes: name %q is already registered", name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	inRange := false
+	for _, r := range registry.ranges {
+		if !r.contains(c) {
+			continue
+		}
+		if r.owner != owner {
+			return fmt.Errorf("codes: code %d is inside the range reserved by %q", c, r.owner)
+		}
+		inRange = true
+	}
+	if owner != "" && !inRange {
+		return fmt.Errorf("codes: code %d is outside the ranges reserved by %q", c, owner)
+	}
+	if _, ok := registry.codes[c]; ok {
+		return fmt.Errorf("codes: code %d is already registered", c)
+	}
+	if _, ok := registry.byName[name]; ok {
+		return fmt.Errorf("codes: name %q is already registered", name)
+	}
+	registry.codes[c] = customCode{name: name, message: message}
+	registry.byName[name] = c
+	return nil
+}
+
+// NextFree returns the smallest code, greater or equal than MinCustomCode, that
+// can be passed to Register: it is not registered and it is not inside a range
+// reserved with ReserveRange. It returns an error if every code up to the largest
+// Code is taken. It is safe for concurrent use with Register, but the code may
+// be taken by another goroutine before it is registered.
+func NextFree() (Code, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c := MinCustomCode
+	for {
+		next := c
+		if _, ok := registry.codes[c]; ok {
+			next = c + 1
+		}
+		for _, r := range registry.ranges {
+			if r.contains(c) {
+				next = r.hi + 1
+				break
+			}
+		}
+		if next == c {
+			return c, nil
+		}
+		// the code c was taken and it was the largest one
+		if next < c {
+			return 0, fmt.Errorf("codes: no free custom code")
+		}
+		c = next
+	}
+}
+
+// lookupCustom returns the registered custom code c.
+func lookupCustom(c Code) (customCode, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	cc, ok := registry.codes[c]
+	return cc, ok
+}
+
+// customCodes returns the registered custom codes in increasing order.
+func customCodes() []Code {
+	registry.RLock()
+	defer registry.RUnlock()
+	custom := make([]Code, 0, len(registry.codes))
+	for c := range registry.codes {
+		custom = append(custom, c)
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i] < custom[j] })
+	return custom
+}
+
+// lookupCustomName returns the registered custom code with the given name.
+func lookupCustomName(name string) (Code, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.byName[name]
+	return c, ok
+}
+
+// codeName returns the snake-case identifier of c, built-in or custom.
+func codeName(c Code) (string, bool) {
+	if name, ok := names[c]; ok {
+		return name, true
+	}
+	if cc, ok := lookupCustom(c); ok {
+		return cc.name, true
+	}
+	return "", false
+}
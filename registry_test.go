@@ -0,0 +1,188 @@
+package codes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+// restoreRegistry restores the registry to its current state when t ends,
+// so the codes and ranges added by t do not leak into other tests.
+func restoreRegistry(t *testing.T) {
+	t.Helper()
+	registry.Lock()
+	codes := make(map[Code]customCode, len(registry.codes))
+	for c, cc := range registry.codes {
+		codes[c] = cc
+	}
+	byName := make(map[string]Code, len(registry.byName))
+	for name, c := range registry.byName {
+		byName[name] = c
+	}
+	ranges := append([]codeRange(nil), registry.ranges...)
+	registry.Unlock()
+	t.Cleanup(func() {
+		registry.Lock()
+		registry.codes = codes
+		registry.byName = byName
+		registry.ranges = ranges
+		registry.Unlock()
+	})
+}
+
+func TestRegister(t *testing.T) {
+	restoreRegistry(t)
+	const c = Code(2000)
+	if err := Register(c, "Quota_Exceeded", "quota exceeded"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Valid() || c.Name() != "quota_exceeded" || c.String() != "quota exceeded" {
+		t.Errorf("registered code = %v, %q, %q", c.Valid(), c.Name(), c.String())
+	}
+	if got, err := ParseCode("quota_exceeded"); err != nil || got != c {
+		t.Errorf("ParseCode(quota_exceeded) = %d, %v, want %d", got, err, c)
+	}
+	if data, _ := json.Marshal(c); string(data) != `"quota_exceeded"` {
+		t.Errorf("json.Marshal = %s, want \"quota_exceeded\"", data)
+	}
+	if e := NewErr(c, ""); e.Code != c || e.Message != "quota exceeded" {
+		t.Errorf("NewErr = %v, want the registered code and message", e)
+	}
+}
+
+func TestRegisterRejects(t *testing.T) {
+	restoreRegistry(t)
+	if err := Register(2001, "test_duplicate", ""); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		code Code
+		name string
+	}{
+		{NotFound, "test_builtin"},
+		{MinCustomCode - 1, "test_reserved"},
+		{2001, "test_other_name"},
+		{2002, "test_duplicate"},
+		{2003, "not_found"},
+		{2004, " "},
+	}
+	for _, tt := range tests {
+		if err := Register(tt.code, tt.name, ""); err == nil {
+			t.Errorf("Register(%d, %q) succeeded, want an error", tt.code, tt.name)
+		}
+	}
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	restoreRegistry(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every code is registered twice, so half the calls must fail
+			c := Code(2100 + i/2)
+			errs <- Register(c, fmt.Sprintf("test_concurrent_%d", i/2), "")
+			c.Name()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed != 25 {
+		t.Errorf("%d registrations failed, want 25", failed)
+	}
+}
+
+func TestReserveRange(t *testing.T) {
+	restoreRegistry(t)
+	if err := ReserveRange("storage", 10000, 10099); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReserveRange("auth", 10100, 10199); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(10500, "test_unreserved", ""); err != nil {
+		t.Fatal(err)
+	}
+	rejected := []struct {
+		owner  string
+		lo, hi Code
+	}{
+		{"search", 10050, 10150},
+		{"search", 9000, 10000},
+		{"search", 10199, 10199},
+		{"search", 10400, 10600},
+		{"search", 500, 600},
+		{"search", 10300, 10200},
+		{"", 11000, 11099},
+	}
+	for _, r := range rejected {
+		if err := ReserveRange(r.owner, r.lo, r.hi); err == nil {
+			t.Errorf("ReserveRange(%q, %d, %d) succeeded, want an error", r.owner, r.lo, r.hi)
+		}
+	}
+
+	if err := RegisterOwned("storage", 10005, "test_disk_full", "disk full"); err != nil {
+		t.Errorf("RegisterOwned in range: %v", err)
+	}
+	if err := Register(10006, "test_unowned", ""); err == nil {
+		t.Error("Register inside a reserved range succeeded")
+	}
+	if err := RegisterOwned("auth", 10007, "test_other_owner", ""); err == nil {
+		t.Error("RegisterOwned in the range of another owner succeeded")
+	}
+	if err := RegisterOwned("storage", 10200, "test_outside", ""); err == nil {
+		t.Error("RegisterOwned outside the ranges of the owner succeeded")
+	}
+	if err := RegisterOwned("", 10008, "test_no_owner", ""); err == nil {
+		t.Error("RegisterOwned without owner succeeded")
+	}
+}
+
+func TestNextFree(t *testing.T) {
+	restoreRegistry(t)
+	c, err := NextFree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c < MinCustomCode || c.Valid() {
+		t.Fatalf("NextFree() = %d, want an unregistered custom code", c)
+	}
+	if err := Register(c, "test_next_free", ""); err != nil {
+		t.Fatal(err)
+	}
+	next, err := NextFree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next <= c {
+		t.Errorf("NextFree() after registering %d = %d, want a greater code", c, next)
+	}
+
+	if err := ReserveRange("test_next_free", next, next+9); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NextFree(); err != nil || got != next+10 {
+		t.Errorf("NextFree() after reserving [%d, %d] = %d, %v, want %d", next, next+9, got, err, next+10)
+	}
+}
+
+func TestNextFreeExhausted(t *testing.T) {
+	restoreRegistry(t)
+	registry.Lock()
+	registry.ranges = append(registry.ranges, codeRange{owner: "test_all", lo: MinCustomCode, hi: math.MaxUint32})
+	registry.Unlock()
+
+	if c, err := NextFree(); err == nil {
+		t.Errorf("NextFree() with every code reserved = %d, want an error", c)
+	}
+}
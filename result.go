@@ -0,0 +1,49 @@
+package codes
+
+import "encoding/json"
+
+// A Result holds either the value of a successful operation or the Err
+// that made it fail. It is encoded as {"data":...} or {"error":{...}}.
+type Result[T any] struct {
+	Value T
+	Err   *Err
+}
+
+// Ok returns a successful Result holding v.
+func Ok[T any](v T) Result[T] {
+	return Result[T]{Value: v}
+}
+
+// Fail returns a failed Result holding e.
+func Fail[T any](e *Err) Result[T] {
+	return Result[T]{Err: e}
+}
+
+// IsOk reports whether the Result is successful.
+func (r Result[T]) IsOk() bool {
+	return r.Err == nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r Result[T]) MarshalJSON() ([]byte, error) {
+	if r.Err != nil {
+		return json.Marshal(errorResponseJSON{Err: r.Err})
+	}
+	return json.Marshal(struct {
+		Data T `json:"data"`
+	}{r.Value})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Result[T]) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Data T    `json:"data"`
+		Err  *Err `json:"error"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	r.Value = v.Data
+	r.Err = v.Err
+	return nil
+}
@@ -0,0 +1,42 @@
+package codes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type file struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func TestResult(t *testing.T) {
+	ok := Ok(file{Name: "a", Size: 3})
+	if !ok.IsOk() {
+		t.Error("Ok(...).IsOk() = false")
+	}
+	data, err := json.Marshal(ok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"data":{"name":"a","size":3}}`; string(data) != want {
+		t.Errorf("json.Marshal(Ok) = %s, want %s", data, want)
+	}
+	var decoded Result[file]
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.IsOk() || decoded.Value != ok.Value {
+		t.Errorf("decoded Ok = %+v, %v", decoded, err)
+	}
+
+	fail := Fail[file](NewErr(NotFound, "no file"))
+	if fail.IsOk() {
+		t.Error("Fail(...).IsOk() = true")
+	}
+	data, _ = json.Marshal(fail)
+	if want := `{"error":{"message":"no file","code":"not_found"}}`; string(data) != want {
+		t.Errorf("json.Marshal(Fail) = %s, want %s", data, want)
+	}
+	decoded = Result[file]{}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.IsOk() || !decoded.Err.Equal(fail.Err) {
+		t.Errorf("decoded Fail = %+v, %v", decoded, err)
+	}
+}
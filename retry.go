@@ -0,0 +1,103 @@
+package codes
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// retryBaseDelay is the delay before the first retry.
+	retryBaseDelay = 100 * time.Millisecond
+
+	// retryMaxDelay bounds the exponential backoff and the Retry-After delay.
+	retryMaxDelay = 30 * time.Second
+)
+
+// idempotentMethods holds the HTTP methods that are safe to retry.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryTransport is the http.RoundTripper returned by NewRetryTransport.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+}
+
+// NewRetryTransport returns an http.RoundTripper that executes requests with next
+// (http.DefaultTransport if nil) and retries them up to maxRetries times when the
+// response is an error whose Code is Retryable. It waits with exponential backoff
+// between attempts, or for the delay given by the Retry-After header if present,
+// which is capped at 30 seconds like the backoff.
+// Only idempotent methods are retried, and only if their body can be replayed.
+// The last response is returned unchanged.
+func NewRetryTransport(next http.RoundTripper, maxRetries int) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{next: next, maxRetries: maxRetries}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := idempotentMethods[req.Method] && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	for attempt := 0; ; attempt++ {
+		res, err := t.next.RoundTrip(req)
+		if err != nil || !retryable || attempt >= t.maxRetries || res.StatusCode < 400 {
+			return res, err
+		}
+		e := ErrFromResponse(res)
+		if !e.Code.Retryable() {
+			return res, nil
+		}
+		delay := retryDelay(NewErrorResponse(res, e), attempt)
+		if res.Body != nil {
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay returns the delay before the retry following the given attempt,
+// which failed with r: its Retry-After delay clamped to [0, retryMaxDelay],
+// or the exponential backoff if it has none.
+func retryDelay(r *ErrorResponse, attempt int) time.Duration {
+	delay, ok := r.RetryAfter()
+	switch {
+	case !ok || delay < 0:
+		return backoff(attempt)
+	case delay > retryMaxDelay:
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func backoff(attempt int) time.Duration {
+	d := retryBaseDelay << uint(attempt)
+	if d <= 0 || d > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return d
+}
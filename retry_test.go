@@ -0,0 +1,176 @@
+package codes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer returns a server that fails the first failures requests
+// with status and then succeeds, and the counter of requests it received.
+func newFlakyServer(t *testing.T, status int, failures int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			WriteError(w, r, NewErr(CodeFromHTTPStatus(status), ""))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		failures int32
+		want     int
+		requests int32
+	}{
+		{"unavailable then ok", http.MethodGet, http.StatusServiceUnavailable, 1, http.StatusOK, 2},
+		{"put is idempotent", http.MethodPut, http.StatusServiceUnavailable, 2, http.StatusOK, 3},
+		{"bad input", http.MethodGet, http.StatusBadRequest, 1, http.StatusBadRequest, 1},
+		{"post", http.MethodPost, http.StatusServiceUnavailable, 1, http.StatusServiceUnavailable, 1},
+		{"retries exhausted", http.MethodGet, http.StatusServiceUnavailable, 10, http.StatusServiceUnavailable, 4},
+	}
+	for _, tt := range tests {
+		srv, n := newFlakyServer(t, tt.status, tt.failures)
+		client := &http.Client{Transport: NewRetryTransport(nil, 3)}
+		req, _ := http.NewRequest(tt.method, srv.URL, strings.NewReader("payload"))
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, res.StatusCode, tt.want)
+		}
+		if got := atomic.LoadInt32(n); got != tt.requests {
+			t.Errorf("%s: %d requests, want %d", tt.name, got, tt.requests)
+		}
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		WriteError(w, r, NewErr(Unavailable, ""))
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	client := &http.Client{Transport: NewRetryTransport(nil, 3)}
+	start := time.Now()
+	if _, err := client.Do(req); err == nil {
+		t.Error("Do with a canceled context succeeded")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Do returned after %v, want it to stop waiting when the context is done", d)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, retryBaseDelay},
+		{1, 2 * retryBaseDelay},
+		{3, 8 * retryBaseDelay},
+		{20, retryMaxDelay},
+		{100, retryMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// roundTripperFunc is an http.RoundTripper implemented by a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportNilBody(t *testing.T) {
+	var n int
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		n++
+		status := http.StatusServiceUnavailable
+		if n > 1 {
+			status = http.StatusOK
+		}
+		header := http.Header{"Retry-After": {"0"}}
+		return &http.Response{StatusCode: status, Header: header, Request: req}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	res, err := NewRetryTransport(next, 3).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || n != 2 {
+		t.Errorf("RoundTrip = %d after %d requests, want 200 after 2", res.StatusCode, n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		retryAfter string
+		attempt    int
+		want       time.Duration
+	}{
+		{"", 0, retryBaseDelay},
+		{"", 2, 4 * retryBaseDelay},
+		{"0", 2, 0},
+		{"5", 0, 5 * time.Second},
+		{"-5", 0, 0},
+		{"3600", 0, retryMaxDelay},
+		{strconv.FormatInt(int64(retryMaxDelay/time.Second), 10), 0, retryMaxDelay},
+		{"9223372037", 0, retryMaxDelay},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 0, retryMaxDelay},
+	}
+	for _, tt := range tests {
+		res := newResponse(http.StatusServiceUnavailable, "")
+		if tt.retryAfter != "" {
+			res.Header.Set("Retry-After", tt.retryAfter)
+		}
+		r := NewErrorResponse(res, NewErr(Unavailable, ""))
+		if got := retryDelay(r, tt.attempt); got != tt.want {
+			t.Errorf("retryDelay with Retry-After %q after attempt %d = %v, want %v", tt.retryAfter, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryTransportHugeRetryAfter(t *testing.T) {
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		w.Header().Set("Retry-After", "9223372037")
+		WriteError(w, r, NewErr(Unavailable, ""))
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	client := &http.Client{Transport: NewRetryTransport(nil, 5)}
+	if _, err := client.Do(req); err == nil {
+		t.Error("Do succeeded, want the context error while waiting")
+	}
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Errorf("%d requests, want 1: the Retry-After delay must not be skipped", got)
+	}
+}
@@ -0,0 +1,239 @@
+package codes
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// defaultRedactedParams holds the lower-cased names of the query parameters
+// that carry secrets and must never be exposed.
+var defaultRedactedParams = []string{
+	"token",
+	"access_token",
+	"api_key",
+	"password",
+	"secret",
+}
+
+// redactedParams holds the lower-cased names of the parameters currently redacted.
+var redactedParams = struct {
+	sync.RWMutex
+	names map[string]bool
+}{names: newRedactedParams()}
+
+func newRedactedParams(names ...string) map[string]bool {
+	m := map[string]bool{}
+	for _, name := range defaultRedactedParams {
+		m[name] = true
+	}
+	for _, name := range names {
+		m[strings.ToLower(name)] = true
+	}
+	return m
+}
+
+// SetRedactedParams sets the names of the sensitive parameters to redact,
+// in addition to the defaults (token, access_token, api_key, password and secret),
+// replacing the ones set by previous calls. Names are matched case-insensitively.
+// The setting is global and is meant to be applied at startup.
+func SetRedactedParams(names ...string) {
+	m := newRedactedParams(names...)
+	redactedParams.Lock()
+	redactedParams.names = m
+	redactedParams.Unlock()
+}
+
+// isRedactedParam reports whether the parameter name carries a secret.
+func isRedactedParam(name string) bool {
+	redactedParams.RLock()
+	defer redactedParams.RUnlock()
+	return redactedParams.names[strings.ToLower(name)]
+}
+
+// redactedPaths holds the patterns of the path segments to redact.
+var redactedPaths = struct {
+	sync.RWMutex
+	patterns []*regexp.Regexp
+}{}
+
+// SetRedactedPathPatterns sets the patterns that match secrets embedded in URL
+// paths, e.g. regexp.MustCompile(`^/reset/([^/]+)/confirm$`), replacing the ones
+// set by previous calls. The text matched by each capturing group, or by the whole
+// pattern if it has no groups, is redacted. No path is redacted by default.
+// The setting is global and is meant to be applied at startup.
+func SetRedactedPathPatterns(patterns ...*regexp.Regexp) {
+	redactedPaths.Lock()
+	redactedPaths.patterns = append([]*regexp.Regexp(nil), patterns...)
+	redactedPaths.Unlock()
+}
+
+// redactPath returns path with the secrets matched by the redacted path
+// patterns replaced, and whether anything was redacted.
+func redactPath(path string) (string, bool) {
+	redactedPaths.RLock()
+	defer redactedPaths.RUnlock()
+	redacted := false
+	for _, re := range redactedPaths.patterns {
+		matches := re.FindAllStringSubmatchIndex(path, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			groups := m[2:]
+			if len(groups) == 0 {
+				groups = m[:2]
+			}
+			for i := 0; i < len(groups); i += 2 {
+				start, end := groups[i], groups[i+1]
+				if start < last || start == end {
+					continue
+				}
+				b.WriteString(path[last:start])
+				b.WriteString("REDACTED")
+				last = end
+				redacted = true
+			}
+		}
+		b.WriteString(path[last:])
+		path = b.String()
+	}
+	return path, redacted
+}
+
+// sanitizeURL redacts the sensitive parameters and path segments from the URL
+// which may be exposed to the user, specifically in the ErrorResponse error message.
+// Parameter names are matched case-insensitively. The given URL is not modified.
+func sanitizeURL(uri *url.URL) *url.URL {
+	if uri == nil {
+		return nil
+	}
+	params := uri.Query()
+	redacted := false
+	for name, values := range params {
+		if !isRedactedParam(name) {
+			continue
+		}
+		for i, v := range values {
+			if len(v) > 0 {
+				values[i] = "REDACTED"
+				redacted = true
+			}
+		}
+	}
+	path, pathRedacted := redactPath(uri.Path)
+	if !redacted && !pathRedacted {
+		return uri
+	}
+	u := *uri
+	if redacted {
+		u.RawQuery = params.Encode()
+	}
+	if pathRedacted {
+		u.Path = path
+		u.RawPath = ""
+	}
+	return &u
+}
+
+// sensitiveHeaders holds the headers removed by ErrorResponse.Redacted.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Redacted returns a copy of the ErrorResponse that is safe to log: the request URL
+// is sanitized and the Authorization and Cookie headers are removed from the
+// response and its request. The receiver is not modified.
+func (r *ErrorResponse) Redacted() *ErrorResponse {
+	c := *r
+	if r.Response == nil {
+		return &c
+	}
+	res := *r.Response
+	res.Header = redactHeader(r.Response.Header)
+	if r.Response.Request != nil {
+		req := *r.Response.Request
+		req.Header = redactHeader(req.Header)
+		req.URL = sanitizeURL(req.URL)
+		res.Request = &req
+	}
+	c.Response = &res
+	return &c
+}
+
+// redactHeader returns a copy of h without the sensitive headers.
+func redactHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	c := h.Clone()
+	for _, name := range sensitiveHeaders {
+		c.Del(name)
+	}
+	return c
+}
+
+// SanitizeBody returns a copy of a request body that is safe to log, with the values
+// of the sensitive keys redacted, using the same names as the query parameters (see
+// SetRedactedParams). JSON (application/json and +json types) and form-encoded
+// (application/x-www-form-urlencoded) bodies are supported; JSON objects are
+// re-encoded with their keys sorted. Other or non-parseable bodies are returned unchanged.
+func SanitizeBody(contentType string, body []byte) []byte {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return body
+		}
+		data, err := json.Marshal(redactJSON(v))
+		if err != nil {
+			return body
+		}
+		return data
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		for name, vs := range values {
+			if isRedactedParam(name) {
+				for i := range vs {
+					vs[i] = "REDACTED"
+				}
+			}
+		}
+		return []byte(values.Encode())
+	default:
+		return body
+	}
+}
+
+// redactJSON redacts the values of the sensitive keys of the decoded JSON value v.
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if isRedactedParam(k) {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = redactJSON(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactJSON(val)
+		}
+	}
+	return v
+}
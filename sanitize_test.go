@@ -0,0 +1,148 @@
+package codes
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeURL(t *testing.T) {
+	raw := "https://example.com/files?Token=t1&access_token=t2&API_KEY=k&password=p&secret=s&page=2&empty_token="
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sanitizeURL(u).Query()
+	for _, name := range []string{"Token", "access_token", "API_KEY", "password", "secret"} {
+		if v := got.Get(name); v != "REDACTED" {
+			t.Errorf("%s = %q, want REDACTED", name, v)
+		}
+	}
+	if v := got.Get("page"); v != "2" {
+		t.Errorf("page = %q, want 2", v)
+	}
+	if u.String() != raw {
+		t.Errorf("sanitizeURL modified its argument to %s", u)
+	}
+	if sanitizeURL(nil) != nil {
+		t.Error("sanitizeURL(nil) != nil")
+	}
+}
+
+func TestErrorResponseSanitizesURL(t *testing.T) {
+	res := newResponse(404, "")
+	res.Request.URL.RawQuery = "token=t1&api_key=k"
+	msg := NewErrorResponse(res, NewErr(NotFound, "")).Error()
+	if strings.Contains(msg, "t1") || strings.Contains(msg, "=k") {
+		t.Errorf("Error() = %q, want the secrets redacted", msg)
+	}
+}
+
+func TestSetRedactedParams(t *testing.T) {
+	SetRedactedParams("sig", "Signature")
+	defer SetRedactedParams()
+
+	u, _ := url.Parse("https://example.com/dl?sig=abc&SIGNATURE=def&token=ghi&page=1")
+	got := sanitizeURL(u).Query()
+	for _, name := range []string{"sig", "SIGNATURE", "token"} {
+		if got.Get(name) != "REDACTED" {
+			t.Errorf("%s = %q, want REDACTED", name, got.Get(name))
+		}
+	}
+	if got.Get("page") != "1" {
+		t.Errorf("page = %q, want 1", got.Get("page"))
+	}
+
+	SetRedactedParams()
+	if got := sanitizeURL(u).Query(); got.Get("sig") != "abc" || got.Get("token") != "REDACTED" {
+		t.Errorf("after a reset, query = %v, want only the defaults redacted", got)
+	}
+}
+
+func TestErrorResponseRedacted(t *testing.T) {
+	res := newResponse(401, "")
+	res.Header.Set("Set-Cookie", "session=1")
+	res.Header.Set("X-Request-Id", "abc")
+	res.Request.Header.Set("Authorization", "Bearer secret")
+	res.Request.Header.Set("Cookie", "session=1")
+	orig := NewErrorResponse(res, NewErr(Unauthenticated, ""))
+
+	c := orig.Redacted()
+	if c.Response.Header.Get("Set-Cookie") != "" || c.Response.Request.Header.Get("Authorization") != "" ||
+		c.Response.Request.Header.Get("Cookie") != "" {
+		t.Errorf("Redacted() kept sensitive headers: %v, %v", c.Response.Header, c.Response.Request.Header)
+	}
+	if c.RequestID() != "abc" {
+		t.Errorf("Redacted() dropped the request ID")
+	}
+	if q := c.Response.Request.URL.RawQuery; strings.Contains(q, "secret") {
+		t.Errorf("Redacted() query = %q, want it sanitized", q)
+	}
+
+	if res.Request.Header.Get("Authorization") != "Bearer secret" || res.Header.Get("Set-Cookie") == "" {
+		t.Error("Redacted() modified the headers of the original")
+	}
+	if res.Request.URL.Query().Get("token") != "secret" || orig.Response != res {
+		t.Error("Redacted() modified the original")
+	}
+
+	if c := NewErrorResponse(nil, nil).Redacted(); c.Response != nil {
+		t.Errorf("Redacted() without response = %v", c)
+	}
+}
+
+func TestSanitizeURLPath(t *testing.T) {
+	SetRedactedPathPatterns(
+		regexp.MustCompile(`^/reset/([^/]+)/confirm$`),
+		regexp.MustCompile(`sk_[a-z0-9]+`),
+	)
+	defer SetRedactedPathPatterns()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com/reset/abc123/confirm", "https://example.com/reset/REDACTED/confirm"},
+		{"https://example.com/keys/sk_live42/usage", "https://example.com/keys/REDACTED/usage"},
+		{"https://example.com/reset/confirm?token=abc123", "https://example.com/reset/confirm?token=REDACTED"},
+		{"https://example.com/reset/abc/confirm?page=2", "https://example.com/reset/REDACTED/confirm?page=2"},
+		{"https://example.com/files/a", "https://example.com/files/a"},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.in)
+		if got := sanitizeURL(u).String(); got != tt.want {
+			t.Errorf("sanitizeURL(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+		if u.String() != tt.in {
+			t.Errorf("sanitizeURL modified its argument to %s", u)
+		}
+	}
+}
+
+func TestSanitizeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json", "application/json", `{"user":"ana","password":"hunter2"}`, `{"password":"REDACTED","user":"ana"}`},
+		{"json with charset", "application/json; charset=utf-8", `{"Password":"hunter2"}`, `{"Password":"REDACTED"}`},
+		{"nested json", "application/merge-patch+json", `{"auth":[{"token":"t","id":12345678901234567890}]}`, `{"auth":[{"id":12345678901234567890,"token":"REDACTED"}]}`},
+		{"form", "application/x-www-form-urlencoded", "user=ana&password=hunter2", "password=REDACTED&user=ana"},
+		{"invalid json", "application/json", `{"password":`, `{"password":`},
+		{"invalid form", "application/x-www-form-urlencoded", "password=%zz", "password=%zz"},
+		{"plain text", "text/plain", "password=hunter2", "password=hunter2"},
+		{"invalid content type", "", `{"password":"hunter2"}`, `{"password":"hunter2"}`},
+	}
+	for _, tt := range tests {
+		body := []byte(tt.body)
+		if got := string(SanitizeBody(tt.contentType, body)); got != tt.want {
+			t.Errorf("%s: SanitizeBody = %s, want %s", tt.name, got, tt.want)
+		}
+		if !bytes.Equal(body, []byte(tt.body)) {
+			t.Errorf("%s: SanitizeBody modified its argument", tt.name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package codes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// WriteError writes e to w with the HTTP status given by e.Code.HTTPStatus().
+// A nil e is written as an Internal error.
+// The body is a JSON error envelope, unless the Accept header of r prefers
+// text/plain, in which case a single "code: message" line is written.
+// A nil r always gets JSON. If the X-Request-Id response header is set,
+// it is included in the envelope.
+func WriteError(w http.ResponseWriter, r *http.Request, e *Err) {
+	if e == nil {
+		e = NewErr(Internal, "")
+	}
+	e = notifyWriteError(w, e)
+	if r != nil && prefersPlainText(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(e.Code.HTTPStatus())
+		fmt.Fprintf(w, "%s: %s\n", e.Code.Name(), e.Message)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Code.HTTPStatus())
+	json.NewEncoder(w).Encode(errorResponseJSON{Err: e, RequestID: w.Header().Get(headerRequestID)})
+}
+
+// prefersPlainText reports whether the Accept header value gives text/plain
+// a higher quality than application/json.
+func prefersPlainText(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	var textQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		switch mediaType {
+		case "text/plain", "text/*":
+			textQ = math.Max(textQ, q)
+		case "application/json", "application/*":
+			jsonQ = math.Max(jsonQ, q)
+		case "*/*":
+			textQ = math.Max(textQ, q)
+			jsonQ = math.Max(jsonQ, q)
+		}
+	}
+	return textQ > jsonQ
+}
+
+// Recoverer is a middleware that recovers from panics in next, logs them
+// and writes an Internal error to the client. Panics with http.ErrAbortHandler
+// are propagated to preserve their standard semantics.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			log.Printf("codes: panic serving %s %s: %v\n%s", r.Method, sanitizeURL(r.URL), p, debug.Stack())
+			WriteError(w, r, NewErr(Internal, ""))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Abort writes an Err with the given code and msg to w in reply to r,
+// mirroring http.Error. If msg is empty, the default code message will be used.
+func Abort(w http.ResponseWriter, r *http.Request, c Code, msg string) {
+	WriteError(w, r, NewErr(c, msg))
+}
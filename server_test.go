@@ -0,0 +1,151 @@
+package codes
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// decodeRecorded decodes the error envelope written to rec.
+func decodeRecorded(t *testing.T, rec *httptest.ResponseRecorder) *Err {
+	t.Helper()
+	var v struct {
+		Err *Err `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("invalid envelope %q: %v", rec.Body.String(), err)
+	}
+	if v.Err == nil {
+		t.Fatalf("envelope %q has no error", rec.Body.String())
+	}
+	return v.Err
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, nil, NewErr(NotFound, "no such file"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var v map[string]map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if e := v["error"]; e["code"] != "not_found" || e["message"] != "no such file" {
+		t.Errorf("body = %s, want the error envelope", rec.Body)
+	}
+}
+
+func TestWriteErrorNil(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, nil, nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if e := decodeRecorded(t, rec); e.Code != Internal {
+		t.Errorf("code = %v, want internal", e.Code.Name())
+	}
+}
+
+func TestRecoverer(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files?token=secret", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if e := decodeRecorded(t, rec); e.Code != Internal {
+		t.Errorf("code = %v, want internal", e.Code.Name())
+	}
+	if !strings.Contains(logs.String(), "boom") || strings.Contains(logs.String(), "secret") {
+		t.Errorf("log = %q, want the panic with the URL sanitized", logs.String())
+	}
+}
+
+func TestRecovererAbortHandler(t *testing.T) {
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", p)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestAbort(t *testing.T) {
+	tests := []struct {
+		msg, want string
+	}{
+		{"slow down", "slow down"},
+		{"", RateLimited.String()},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		Abort(rec, httptest.NewRequest(http.MethodGet, "/", nil), RateLimited, tt.msg)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+		if e := decodeRecorded(t, rec); e.Code != RateLimited || e.Message != tt.want {
+			t.Errorf("body = %v, want rate_limited: %s", e, tt.want)
+		}
+	}
+}
+
+func TestWriteErrorNegotiation(t *testing.T) {
+	tests := []struct {
+		accept string
+		plain  bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"text/plain", true},
+		{"text/plain;q=0.5, application/json", false},
+		{"application/json;q=0.5, text/plain", true},
+		{"*/*", false},
+		{"text/html", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		WriteError(rec, req, NewErr(NotFound, "no such file"))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Accept %q: status = %d, want 404", tt.accept, rec.Code)
+		}
+		contentType := rec.Header().Get("Content-Type")
+		if tt.plain {
+			if !strings.HasPrefix(contentType, "text/plain") || rec.Body.String() != "not_found: no such file\n" {
+				t.Errorf("Accept %q: got %q %q, want a plain text line", tt.accept, contentType, rec.Body)
+			}
+			continue
+		}
+		if contentType != "application/json" {
+			t.Errorf("Accept %q: Content-Type = %q, want application/json", tt.accept, contentType)
+			continue
+		}
+		if e := decodeRecorded(t, rec); e.Code != NotFound {
+			t.Errorf("Accept %q: code = %v, want not_found", tt.accept, e.Code.Name())
+		}
+	}
+}
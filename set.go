@@ -0,0 +1,43 @@
+package codes
+
+// A CodeSet is a set of codes, e.g. to hold the codes a middleware
+// should act on.
+type CodeSet map[Code]struct{}
+
+// NewCodeSet returns a CodeSet with the given codes.
+func NewCodeSet(codes ...Code) CodeSet {
+	s := make(CodeSet, len(codes))
+	for _, c := range codes {
+		s[c] = struct{}{}
+	}
+	return s
+}
+
+// Contains reports whether c is in the set.
+func (s CodeSet) Contains(c Code) bool {
+	_, ok := s[c]
+	return ok
+}
+
+// Union returns a new set with the codes that are in s or in other.
+func (s CodeSet) Union(other CodeSet) CodeSet {
+	u := make(CodeSet, len(s)+len(other))
+	for c := range s {
+		u[c] = struct{}{}
+	}
+	for c := range other {
+		u[c] = struct{}{}
+	}
+	return u
+}
+
+// Intersect returns a new set with the codes that are both in s and in other.
+func (s CodeSet) Intersect(other CodeSet) CodeSet {
+	i := CodeSet{}
+	for c := range s {
+		if other.Contains(c) {
+			i[c] = struct{}{}
+		}
+	}
+	return i
+}
@@ -0,0 +1,27 @@
+package codes
+
+import (
+	"testing"
+)
+
+func TestCodeSet(t *testing.T) {
+	a := NewCodeSet(Internal, Timeout, Unavailable)
+	b := NewCodeSet(Unavailable, RateLimited)
+	if !a.Contains(Timeout) || a.Contains(RateLimited) {
+		t.Errorf("Contains is wrong for %v", a)
+	}
+	u := a.Union(b)
+	if len(u) != 4 || !u.Contains(Internal) || !u.Contains(RateLimited) {
+		t.Errorf("Union = %v, want 4 codes", u)
+	}
+	i := a.Intersect(b)
+	if len(i) != 1 || !i.Contains(Unavailable) {
+		t.Errorf("Intersect = %v, want only unavailable", i)
+	}
+	if len(a) != 3 || len(b) != 2 {
+		t.Error("set operations modified their operands")
+	}
+	if NewCodeSet().Contains(Success) {
+		t.Error("an empty set contains success")
+	}
+}
@@ -0,0 +1,46 @@
+package codes
+
+import "log/slog"
+
+// LogValue implements the slog.LogValuer interface, so an Err is logged as
+// a group with its code, code_name and message, plus its cause if it has one.
+// A nil Err is logged as an empty group.
+func (e *Err) LogValue() slog.Value {
+	return slog.GroupValue(e.logAttrs()...)
+}
+
+func (e *Err) logAttrs() []slog.Attr {
+	if e == nil {
+		return nil
+	}
+	attrs := []slog.Attr{
+		slog.Uint64("code", uint64(e.Code)),
+		slog.String("code_name", e.Code.Name()),
+		slog.String("message", e.Message),
+	}
+	if e.cause != nil {
+		attrs = append(attrs, slog.String("cause", e.cause.Error()))
+	}
+	return attrs
+}
+
+// LogValue implements the slog.LogValuer interface. It takes precedence over the
+// LogValue method of the embedded Err, so the attributes of the Err are followed
+// by the status, method and sanitized URL of the response and the request_id,
+// when they are known.
+func (r *ErrorResponse) LogValue() slog.Value {
+	attrs := r.Err.logAttrs()
+	if r.Response != nil {
+		attrs = append(attrs, slog.Int("status", r.Response.StatusCode))
+		if req := r.Response.Request; req != nil {
+			attrs = append(attrs, slog.String("method", req.Method))
+			if req.URL != nil {
+				attrs = append(attrs, slog.String("url", sanitizeURL(req.URL).String()))
+			}
+		}
+	}
+	if id := r.RequestID(); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	return slog.GroupValue(attrs...)
+}
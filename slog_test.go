@@ -0,0 +1,85 @@
+package codes
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+// captureHandler is a slog.Handler that keeps the records it handles.
+type captureHandler struct {
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *captureHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+// loggedGroup logs v as "err" and returns the attributes of the resolved group.
+func loggedGroup(t *testing.T, v interface{}) map[string]slog.Value {
+	t.Helper()
+	h := &captureHandler{}
+	slog.New(h).Info("failed", "err", v)
+	if len(h.records) != 1 {
+		t.Fatalf("%d records, want 1", len(h.records))
+	}
+	group := map[string]slog.Value{}
+	h.records[0].Attrs(func(a slog.Attr) bool {
+		if a.Key != "err" {
+			return true
+		}
+		val := a.Value.Resolve()
+		if val.Kind() != slog.KindGroup {
+			t.Fatalf("err is logged as %v, want a group", val.Kind())
+		}
+		for _, ga := range val.Group() {
+			group[ga.Key] = ga.Value
+		}
+		return false
+	})
+	return group
+}
+
+func TestErrLogValue(t *testing.T) {
+	group := loggedGroup(t, Wrap(NotFound, errors.New("no rows")))
+	if group["code"].Uint64() != uint64(NotFound) {
+		t.Errorf("code = %v, want %d", group["code"], NotFound)
+	}
+	if group["code_name"].String() != "not_found" {
+		t.Errorf("code_name = %v, want not_found", group["code_name"])
+	}
+	if group["message"].String() != NotFound.String() {
+		t.Errorf("message = %v, want %q", group["message"], NotFound.String())
+	}
+	if group["cause"].String() != "no rows" {
+		t.Errorf("cause = %v, want no rows", group["cause"])
+	}
+
+	if _, ok := loggedGroup(t, NewErr(NotFound, ""))["cause"]; ok {
+		t.Error("an Err without cause is logged with a cause")
+	}
+}
+
+func TestErrorResponseLogValue(t *testing.T) {
+	res := newResponse(404, "")
+	res.Header.Set("X-Request-Id", "req-1")
+	group := loggedGroup(t, NewErrorResponse(res, NewErr(NotFound, "")))
+	if group["code_name"].String() != "not_found" || group["status"].Int64() != 404 ||
+		group["method"].String() != "GET" || group["request_id"].String() != "req-1" {
+		t.Errorf("ErrorResponse is logged as %v", group)
+	}
+	if url := group["url"].String(); url != "http://example.com/files?token=REDACTED" {
+		t.Errorf("url = %q, want it sanitized", url)
+	}
+
+	group = loggedGroup(t, NewErrorResponse(nil, nil))
+	if len(group) != 0 {
+		t.Errorf("ErrorResponse without Err nor response is logged as %v, want an empty group", group)
+	}
+}
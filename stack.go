@@ -0,0 +1,46 @@
+package codes
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+)
+
+// maxStackDepth is the maximum number of frames captured by NewErrWithStack.
+const maxStackDepth = 32
+
+// NewErrWithStack works like NewErr but also captures the call stack of
+// its caller, which is useful for debugging Internal errors.
+// The stack is never serialized.
+func NewErrWithStack(c Code, msg string) *Err {
+	e := NewErr(c, msg)
+	pcs := make([]uintptr, maxStackDepth)
+	// skip runtime.Callers and NewErrWithStack
+	n := runtime.Callers(2, pcs)
+	e.stack = pcs[:n]
+	return e
+}
+
+// StackTrace returns the program counters of the stack captured when the Err
+// was created, or nil if no stack was captured.
+func (e *Err) StackTrace() []uintptr {
+	return e.stack
+}
+
+// FormatStack returns a human-readable representation of the captured stack,
+// one "function\n\tfile:line" entry per frame, suitable for logging.
+func (e *Err) FormatStack() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,37 @@
+package codes
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewErrWithStack(t *testing.T) {
+	e := NewErrWithStack(Internal, "boom")
+	pcs := e.StackTrace()
+	if len(pcs) == 0 {
+		t.Fatal("StackTrace() is empty")
+	}
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	if !strings.HasSuffix(frame.Function, ".TestNewErrWithStack") {
+		t.Errorf("first frame is %s, want the caller of NewErrWithStack", frame.Function)
+	}
+	if stack := e.FormatStack(); !strings.Contains(stack, "stack_test.go") {
+		t.Errorf("FormatStack() = %q, want it to point into stack_test.go", stack)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "stack") {
+		t.Errorf("json.Marshal = %s, want the stack not to be serialized", data)
+	}
+}
+
+func TestStackTraceNotCaptured(t *testing.T) {
+	e := NewErr(Internal, "")
+	if e.StackTrace() != nil || e.FormatStack() != "" {
+		t.Error("NewErr captured a stack")
+	}
+}